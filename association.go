@@ -0,0 +1,207 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AddAssociation appends the related entities to an association of the entity, such as the has many
+// or many to many field Orders of a User. For a many to many association the rows of the join table are
+// inserted, for a has many association the foreign key of the related entities is set. Related
+// entities that don't exist yet are created.
+//
+// Usage:
+//
+//	if err := userRepo.AddAssociation(user, "Roles", &admin, &editor); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - entity: A pointer to the stored entity owning the association.
+// - name: The name of the association field in the struct of the model.
+// - related: Pointers to the related entities, or slices of them.
+//
+// Returns:
+// - nil if the entities are associated.
+// - An error if the association is unknown, if a related entity has the wrong type or if the write fails.
+func (r *Repository[T]) AddAssociation(entity *T, name string, related ...interface{}) error {
+	return r.association(entity, name, related, func(assoc *gorm.Association) error {
+		return assoc.Append(related...)
+	})
+}
+
+// RemoveAssociation removes the related entities from an association of the entity, without deleting
+// them: the rows of the join table are deleted, or the foreign key of the related entities is cleared.
+//
+// Usage:
+//
+//	if err := userRepo.RemoveAssociation(user, "Roles", &editor); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - entity: A pointer to the stored entity owning the association.
+// - name: The name of the association field in the struct of the model.
+// - related: Pointers to the related entities, or slices of them.
+//
+// Returns:
+// - nil if the entities are no longer associated.
+// - An error if the association is unknown, if a related entity has the wrong type or if the write fails.
+func (r *Repository[T]) RemoveAssociation(entity *T, name string, related ...interface{}) error {
+	return r.association(entity, name, related, func(assoc *gorm.Association) error {
+		return assoc.Delete(related...)
+	})
+}
+
+// ReplaceAssociation replaces the related entities of an association of the entity, so only the
+// given ones remain associated. Passing no related entity clears the association.
+//
+// Usage:
+//
+//	if err := userRepo.ReplaceAssociation(user, "Roles", &viewer); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - entity: A pointer to the stored entity owning the association.
+// - name: The name of the association field in the struct of the model.
+// - related: Pointers to the related entities, or slices of them.
+//
+// Returns:
+// - nil if the association is replaced.
+// - An error if the association is unknown, if a related entity has the wrong type or if the write fails.
+func (r *Repository[T]) ReplaceAssociation(entity *T, name string, related ...interface{}) error {
+	return r.association(entity, name, related, func(assoc *gorm.Association) error {
+		return assoc.Replace(related...)
+	})
+}
+
+// ClearAssociation removes all the related entities from an association of the entity, without
+// deleting them.
+//
+// Usage:
+//
+//	if err := userRepo.ClearAssociation(user, "Roles"); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - entity: A pointer to the stored entity owning the association.
+// - name: The name of the association field in the struct of the model.
+//
+// Returns:
+// - nil if the association is cleared.
+// - An error if the association is unknown or if the write fails.
+func (r *Repository[T]) ClearAssociation(entity *T, name string) error {
+	return r.association(entity, name, nil, func(assoc *gorm.Association) error {
+		return assoc.Clear()
+	})
+}
+
+// CountAssociation counts the related entities of an association of the entity.
+//
+// Usage:
+//
+//	roles, err := userRepo.CountAssociation(user, "Roles")
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - entity: A pointer to the stored entity owning the association.
+// - name: The name of the association field in the struct of the model.
+//
+// Returns:
+// - The number of related entities.
+// - An error if the association is unknown or if the count fails.
+func (r *Repository[T]) CountAssociation(entity *T, name string) (int64, error) {
+	var count int64
+
+	err := r.association(entity, name, nil, func(assoc *gorm.Association) error {
+		count = assoc.Count()
+		return assoc.Error
+	})
+
+	return count, err
+}
+
+// association validates the arguments and runs the operation on the association of the entity.
+func (r *Repository[T]) association(entity *T, name string, related []interface{}, op func(assoc *gorm.Association) error) error {
+	if entity == nil {
+		return errors.New("the entity should not be nil")
+	}
+
+	rel, err := r.relationship(name)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range related {
+		if err := checkRelated(rel, value); err != nil {
+			return err
+		}
+	}
+
+	// The association may issue several statements, so it is only retried as a whole transaction.
+	err = r.transaction(func(tx *gorm.DB) error {
+		assoc := tx.Model(entity).Association(rel.Name)
+		if assoc.Error != nil {
+			return assoc.Error
+		}
+
+		return op(assoc)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// A belongs to association changes the foreign key of the entity itself.
+	r.invalidate(r.primaryKeyValue(entity))
+
+	return nil
+}
+
+// relationship returns the relationship of the model with the given name.
+func (r *Repository[T]) relationship(name string) (*schema.Relationship, error) {
+	if rel, ok := r.schema.Relationships.Relations[name]; ok {
+		return rel, nil
+	}
+
+	names := make([]string, 0, len(r.schema.Relationships.Relations))
+	for relName := range r.schema.Relationships.Relations {
+		names = append(names, relName)
+	}
+
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown association: %q, %s has no association", name, r.schema.Name)
+	}
+
+	return nil, fmt.Errorf("unknown association: %q, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// checkRelated checks that the value is an entity of the related model, a pointer to it or a slice of them.
+func checkRelated(rel *schema.Relationship, value interface{}) error {
+	if value == nil {
+		return fmt.Errorf("invalid %s association: the related entity should not be nil", rel.Name)
+	}
+
+	typ := reflect.TypeOf(value)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+
+	if typ != rel.FieldSchema.ModelType {
+		return fmt.Errorf("invalid %s association: expected %s, got %T", rel.Name, rel.FieldSchema.Name, value)
+	}
+
+	return nil
+}
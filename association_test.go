@@ -0,0 +1,116 @@
+package gormet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAssocUser struct {
+	ID       uint
+	Name     string
+	Roles    []testAssocRole    `gorm:"many2many:test_assoc_user_roles"`
+	Sessions []testAssocSession `gorm:"foreignKey:UserID"`
+}
+
+type testAssocRole struct {
+	ID   uint
+	Name string
+}
+
+type testAssocSession struct {
+	ID     uint
+	UserID *uint
+	Token  string
+}
+
+func TestAssociation(t *testing.T) {
+	db := getGormConnection(t, &testAssocUser{})
+	assert.Nil(t, db.AutoMigrate(&testAssocRole{}, &testAssocSession{}))
+
+	repo, err := New[testAssocUser](db)
+	assert.Nil(t, err)
+
+	newUser := func() *testAssocUser {
+		user := &testAssocUser{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(user))
+
+		return user
+	}
+
+	t.Run("Many to many", func(t *testing.T) {
+		user := newUser()
+
+		admin := &testAssocRole{Name: "admin"}
+		editor := &testAssocRole{Name: "editor"}
+		viewer := &testAssocRole{Name: "viewer"}
+
+		assert.Nil(t, repo.AddAssociation(user, "Roles", admin, editor))
+		assert.NotZero(t, admin.ID)
+
+		count, err := repo.CountAssociation(user, "Roles")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		assert.Nil(t, repo.RemoveAssociation(user, "Roles", editor))
+
+		count, _ = repo.CountAssociation(user, "Roles")
+		assert.Equal(t, int64(1), count)
+
+		assert.Nil(t, repo.ReplaceAssociation(user, "Roles", []*testAssocRole{editor, viewer}))
+
+		stored, err := repo.SearchAll("id = ?", user.ID, SearchPreload("Roles"))
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"editor", "viewer"}, []string{stored[0].Roles[0].Name, stored[0].Roles[1].Name})
+
+		assert.Nil(t, repo.ClearAssociation(user, "Roles"))
+
+		count, _ = repo.CountAssociation(user, "Roles")
+		assert.Zero(t, count)
+
+		// The roles are only dissociated.
+		var roles int64
+		assert.Nil(t, db.Model(&testAssocRole{}).Where("id IN ?", []uint{admin.ID, editor.ID, viewer.ID}).Count(&roles).Error)
+		assert.Equal(t, int64(3), roles)
+	})
+
+	t.Run("Has many", func(t *testing.T) {
+		user := newUser()
+		sessions := []testAssocSession{{Token: "a"}, {Token: "b"}}
+
+		assert.Nil(t, repo.AddAssociation(user, "Sessions", &sessions))
+		assert.Equal(t, user.ID, *sessions[0].UserID)
+
+		count, err := repo.CountAssociation(user, "Sessions")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		assert.Nil(t, repo.RemoveAssociation(user, "Sessions", &sessions[0]))
+
+		count, _ = repo.CountAssociation(user, "Sessions")
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		user := newUser()
+
+		err := repo.AddAssociation(user, "Groups", &testAssocRole{})
+		assert.Equal(t, `unknown association: "Groups", expected one of: Roles, Sessions`, err.Error())
+
+		err = repo.AddAssociation(user, "Roles", &testAssocSession{})
+		assert.Equal(t, "invalid Roles association: expected testAssocRole, got *gormet.testAssocSession", err.Error())
+
+		err = repo.AddAssociation(user, "Roles", nil)
+		assert.NotNil(t, err)
+
+		_, err = repo.CountAssociation(nil, "Roles")
+		assert.Equal(t, "the entity should not be nil", err.Error())
+
+		counters, err := New[testCounter](db)
+		assert.Nil(t, err)
+
+		err = counters.ClearAssociation(&testCounter{}, "Items")
+		assert.Equal(t, `unknown association: "Items", testCounter has no association`, err.Error())
+	})
+}
@@ -0,0 +1,351 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// BulkOption customizes a single call to UpdateWhere or DeleteWhere. As search options, bulk
+// options are passed after the arguments of the query condition.
+type BulkOption func(*bulkConfig)
+
+// bulkConfig holds the settings assembled from the bulk options of a call.
+type bulkConfig struct {
+	allowGlobal bool // Whether empty criteria may affect all the entities.
+}
+
+// AllowGlobal confirms that UpdateWhere or DeleteWhere may affect all the entities when the criteria
+// is empty. Without it, empty criteria are refused to prevent accidental global writes.
+//
+// Usage:
+// affected, err := repo.DeleteWhere(nil, gormet.AllowGlobal())
+func AllowGlobal() BulkOption {
+	return func(c *bulkConfig) {
+		c.allowGlobal = true
+	}
+}
+
+// UpdateWhere sets the given columns on all the entities in the database matching the criteria.
+//
+// The columns are validated against the model schema and the update time of the entities is refreshed,
+// as GORM does. The hooks of the repository are not invoked, since no entity is loaded, while the cached
+// entities are invalidated. Empty criteria are refused unless AllowGlobal is passed.
+//
+// Usage:
+// affected, err := repo.UpdateWhere("status = ?", map[string]interface{}{"archived": true}, "done")
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - query: GORM query condition or Specification.
+// - values: The columns to set, by column or field name, with their new values.
+// - args: Arguments for the query condition, optionally followed by AllowGlobal.
+//
+// Returns:
+// - The number of entities updated.
+// - An error if the criteria is empty, if a column is unknown or if the update operation encounters any issues.
+func (r *Repository[T]) UpdateWhere(query interface{}, values map[string]interface{}, args ...interface{}) (int64, error) {
+	args, bulk := splitBulkOptions(args)
+
+	if len(values) == 0 {
+		return 0, errors.New("the values should not be empty")
+	}
+
+	columns, err := bulkColumns(r.schema, values)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.bulkSession(r.db, "update", bulk, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	ids, err := r.bulkIds(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var affected int64
+
+	err = r.retry(func() error {
+		result := tx.Session(&gorm.Session{}).Model(new(T)).Updates(columns)
+		affected = result.RowsAffected
+
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		r.invalidate(id)
+	}
+
+	return affected, nil
+}
+
+// DeleteWhere removes all the entities in the database matching the criteria, according to the
+// configured SoftDeleteStrategy.
+//
+// The hooks of the repository are not invoked, since no entity is loaded, while the cached entities
+// are invalidated. Empty criteria are refused unless AllowGlobal is passed.
+//
+// Usage:
+// affected, err := repo.DeleteWhere(gormet.Between[Session]("expires_at", time.Time{}, time.Now()))
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - query: GORM query condition or Specification.
+// - args: Arguments for the query condition, optionally followed by AllowGlobal.
+//
+// Returns:
+// - The number of entities deleted, 0 if none matches.
+// - An error if the criteria is empty or if the delete operation encounters any issues.
+func (r *Repository[T]) DeleteWhere(query interface{}, args ...interface{}) (int64, error) {
+	args, bulk := splitBulkOptions(args)
+
+	tx, err := r.bulkSession(r.deleteSession(r.db), "delete", bulk, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	ids, err := r.bulkIds(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var affected int64
+
+	err = r.retry(func() error {
+		result := tx.Session(&gorm.Session{}).Delete(new(T))
+		affected = result.RowsAffected
+
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		r.invalidate(id)
+	}
+
+	return affected, nil
+}
+
+// bulkSession applies the criteria of a bulk operation to the connection, refusing empty
+// criteria unless the bulk options allow global operations.
+func (r *Repository[T]) bulkSession(tx *gorm.DB, op string, bulk bulkConfig, query interface{}, args []interface{}) (*gorm.DB, error) {
+	if isEmptyCriteria[T](query) {
+		if !bulk.allowGlobal {
+			return nil, fmt.Errorf("refusing to %s all the entities: the criteria is empty, pass AllowGlobal to confirm", op)
+		}
+
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}), nil
+	}
+
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.Where(query, args...), nil
+}
+
+// bulkIds returns the primary keys of the entities affected by a bulk operation, so they can be
+// invalidated in the cache. Nothing is loaded when the repository has no cache.
+func (r *Repository[T]) bulkIds(tx *gorm.DB) ([]interface{}, error) {
+	if r.config.cache == nil {
+		return nil, nil
+	}
+
+	var ids []interface{}
+
+	err := r.retry(func() error {
+		ids = ids[:0]
+		return tx.Session(&gorm.Session{}).Model(new(T)).Pluck(r.pkName, &ids).Error
+	})
+
+	return ids, err
+}
+
+// UpdateWhere sets the given columns on all the entities matching the criteria.
+func (m *MemoryRepository[T]) UpdateWhere(query interface{}, values map[string]interface{}, args ...interface{}) (int64, error) {
+	args, bulk := splitBulkOptions(args)
+
+	if len(values) == 0 {
+		return 0, errors.New("the values should not be empty")
+	}
+
+	if _, err := bulkColumns(m.schema, values); err != nil {
+		return 0, err
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	indexes, err := m.bulkMatches("update", bulk, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	previous := append([]T(nil), m.store.entities...)
+
+	for _, idx := range indexes {
+		rv := reflect.ValueOf(&m.store.entities[idx]).Elem()
+
+		for name, value := range values {
+			if err := lookUpDBField(m.schema, name).Set(ctx, rv, value); err != nil {
+				m.store.entities = previous
+				return 0, err
+			}
+		}
+
+		m.setAutoTime(rv, false)
+	}
+
+	for _, idx := range indexes {
+		if err := m.checkConstraints(reflect.ValueOf(&m.store.entities[idx]).Elem(), idx); err != nil {
+			m.store.entities = previous
+			return 0, err
+		}
+	}
+
+	return int64(len(indexes)), nil
+}
+
+// DeleteWhere removes all the entities matching the criteria.
+func (m *MemoryRepository[T]) DeleteWhere(query interface{}, args ...interface{}) (int64, error) {
+	args, bulk := splitBulkOptions(args)
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	indexes, err := m.bulkMatches("delete", bulk, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	if field := softDeleteField(m.schema); field != nil && m.config.softDelete != SoftDeleteNever {
+		now := time.Now()
+
+		for _, idx := range indexes {
+			if err := field.Set(context.Background(), reflect.ValueOf(&m.store.entities[idx]).Elem(), now); err != nil {
+				return 0, err
+			}
+		}
+
+		return int64(len(indexes)), nil
+	}
+
+	removed := make(map[int]bool, len(indexes))
+	for _, idx := range indexes {
+		removed[idx] = true
+	}
+
+	kept := m.store.entities[:0]
+	for i, entity := range m.store.entities {
+		if !removed[i] {
+			kept = append(kept, entity)
+		}
+	}
+
+	m.store.entities = kept
+
+	return int64(len(indexes)), nil
+}
+
+// bulkMatches returns the positions of the visible entities matching the criteria of a bulk
+// operation, refusing empty criteria unless the bulk options allow global operations.
+// The caller must hold the store lock.
+func (m *MemoryRepository[T]) bulkMatches(op string, bulk bulkConfig, query interface{}, args []interface{}) ([]int, error) {
+	if isEmptyCriteria[T](query) && !bulk.allowGlobal {
+		return nil, fmt.Errorf("refusing to %s all the entities: the criteria is empty, pass AllowGlobal to confirm", op)
+	}
+
+	match, err := parseCriteria(m.schema, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	for i := range m.store.entities {
+		rv := reflect.ValueOf(&m.store.entities[i]).Elem()
+
+		if m.visible(rv) && match(rv) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
+
+// splitBulkOptions separates the bulk options from the arguments of the query condition and applies them.
+func splitBulkOptions(args []interface{}) ([]interface{}, bulkConfig) {
+	var bulk bulkConfig
+	var rest []interface{}
+
+	for _, arg := range args {
+		if opt, ok := arg.(BulkOption); ok {
+			if opt != nil {
+				opt(&bulk)
+			}
+
+			continue
+		}
+
+		rest = append(rest, arg)
+	}
+
+	return rest, bulk
+}
+
+// bulkColumns converts the keys of the values of an update into column names, validating them against the schema.
+func bulkColumns(sch *schema.Schema, values map[string]interface{}) (map[string]interface{}, error) {
+	columns := make(map[string]interface{}, len(values))
+
+	for name, value := range values {
+		field := lookUpDBField(sch, name)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column: %q", name)
+		}
+
+		columns[field.DBName] = value
+	}
+
+	return columns, nil
+}
+
+// isEmptyCriteria reports whether the criteria matches all the entities: nil, a blank string,
+// an empty map, a zero struct or a Specification matching everything.
+func isEmptyCriteria[T any](query interface{}) bool {
+	switch q := query.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(q) == ""
+	case map[string]interface{}:
+		return len(q) == 0
+	case Specification[T]:
+		return q.op == "ALL"
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(query))
+	return rv.Kind() == reflect.Struct && rv.IsZero()
+}
@@ -0,0 +1,194 @@
+package gormet
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testBulk struct {
+	gorm.Model
+	Name     string `json:"name" gorm:"unique;not null;default:null"`
+	Group    string `json:"group"`
+	Archived bool   `json:"archived"`
+}
+
+func createBulk(t *testing.T, repo RepositoryAPI[testBulk], group string, n int) {
+	for i := 0; i < n; i++ {
+		assert.Nil(t, repo.Create(&testBulk{Name: fmt.Sprintf("%s-%d", group, i), Group: group}))
+	}
+}
+
+func TestUpdateWhere(t *testing.T) {
+	db := getGormConnection(t, &testBulk{})
+
+	repo, err := New[testBulk](db)
+	assert.Nil(t, err)
+
+	memory, err := NewMemory[testBulk]()
+	assert.Nil(t, err)
+
+	for name, api := range map[string]RepositoryAPI[testBulk]{"database": repo, "memory": memory} {
+		t.Run("Update matching entities in "+name, func(t *testing.T) {
+			group, other := uuid.NewString(), uuid.NewString()
+			createBulk(t, api, group, 3)
+			createBulk(t, api, other, 2)
+
+			affected, err := api.UpdateWhere(Eq[testBulk]("group", group), map[string]interface{}{"Archived": true})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), affected)
+
+			count, err := api.Count("`group` = ? AND archived = ?", group, true)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), count)
+
+			count, err = api.Count("`group` = ? AND archived = ?", other, false)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), count)
+		})
+
+		t.Run("Raw criteria with arguments in "+name, func(t *testing.T) {
+			group := uuid.NewString()
+			createBulk(t, api, group, 2)
+
+			affected, err := api.UpdateWhere("`group` = ?", map[string]interface{}{"archived": true}, group)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), affected)
+		})
+
+		t.Run("No match in "+name, func(t *testing.T) {
+			affected, err := api.UpdateWhere(Eq[testBulk]("group", uuid.NewString()), map[string]interface{}{"archived": true})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), affected)
+		})
+
+		t.Run("Unique violation in "+name, func(t *testing.T) {
+			group := uuid.NewString()
+			createBulk(t, api, group, 2)
+
+			_, err := api.UpdateWhere(Eq[testBulk]("group", group), map[string]interface{}{"name": group})
+			assert.True(t, IsDuplicateKey(err))
+
+			count, err := api.Count(Eq[testBulk]("name", group))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), count)
+		})
+
+		t.Run("Invalid values in "+name, func(t *testing.T) {
+			_, err := api.UpdateWhere(Eq[testBulk]("group", "x"), map[string]interface{}{})
+			assert.NotNil(t, err)
+
+			_, err = api.UpdateWhere(Eq[testBulk]("group", "x"), map[string]interface{}{"unknown": 1})
+			assert.Equal(t, `unknown column: "unknown"`, err.Error())
+		})
+
+		t.Run("Empty criteria refused in "+name, func(t *testing.T) {
+			for _, query := range []interface{}{nil, " ", map[string]interface{}{}, testBulk{}} {
+				_, err := api.UpdateWhere(query, map[string]interface{}{"archived": true})
+				assert.Equal(t, "refusing to update all the entities: the criteria is empty, pass AllowGlobal to confirm", err.Error())
+			}
+		})
+
+		t.Run("Global update in "+name, func(t *testing.T) {
+			affected, err := api.UpdateWhere(nil, map[string]interface{}{"archived": false}, AllowGlobal())
+			assert.Nil(t, err)
+			assert.Positive(t, affected)
+
+			count, err := api.Count("archived = ?", true)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), count)
+		})
+	}
+
+	t.Run("Cache invalidated", func(t *testing.T) {
+		cached, err := New[testBulk](db, WithCache(NewMemoryCache(), time.Minute))
+		assert.Nil(t, err)
+
+		group := uuid.NewString()
+		createBulk(t, cached, group, 1)
+
+		entity, err := cached.Get(testBulk{Group: group})
+		assert.Nil(t, err)
+
+		_, err = cached.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		_, err = cached.UpdateWhere(Eq[testBulk]("group", group), map[string]interface{}{"archived": true})
+		assert.Nil(t, err)
+
+		entity, err = cached.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.True(t, entity.Archived)
+	})
+}
+
+func TestDeleteWhere(t *testing.T) {
+	db := getGormConnection(t, &testBulk{})
+
+	repo, err := New[testBulk](db)
+	assert.Nil(t, err)
+
+	memory, err := NewMemory[testBulk]()
+	assert.Nil(t, err)
+
+	for name, api := range map[string]RepositoryAPI[testBulk]{"database": repo, "memory": memory} {
+		t.Run("Delete matching entities in "+name, func(t *testing.T) {
+			group, other := uuid.NewString(), uuid.NewString()
+			createBulk(t, api, group, 3)
+			createBulk(t, api, other, 2)
+
+			affected, err := api.DeleteWhere("`group` = ?", group)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), affected)
+
+			count, err := api.Count(Eq[testBulk]("group", group))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), count)
+
+			count, err = api.Count(Eq[testBulk]("group", other))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), count)
+
+			affected, err = api.DeleteWhere("`group` = ?", group)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), affected)
+		})
+
+		t.Run("Empty criteria refused in "+name, func(t *testing.T) {
+			_, err := api.DeleteWhere(nil)
+			assert.Equal(t, "refusing to delete all the entities: the criteria is empty, pass AllowGlobal to confirm", err.Error())
+
+			_, err = api.DeleteWhere(Specification[testBulk]{op: "ALL"})
+			assert.NotNil(t, err)
+		})
+
+		t.Run("Global delete in "+name, func(t *testing.T) {
+			_, err := api.DeleteWhere(nil, AllowGlobal())
+			assert.Nil(t, err)
+
+			count, err := api.Count(nil)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), count)
+		})
+	}
+
+	t.Run("Physical delete", func(t *testing.T) {
+		hard, err := New[testBulk](db, WithSoftDelete(SoftDeleteNever))
+		assert.Nil(t, err)
+
+		group := uuid.NewString()
+		createBulk(t, hard, group, 2)
+
+		affected, err := hard.DeleteWhere(Eq[testBulk]("group", group))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), affected)
+
+		var count int64
+		db.Unscoped().Model(&testBulk{}).Where("`group` = ?", group).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+}
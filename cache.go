@@ -0,0 +1,98 @@
+package gormet
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache is the storage used by the repository to keep entities retrieved by GetById.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(key string) (interface{}, bool)
+
+	// Set stores the value under key. A ttl of 0 means the value never expires.
+	Set(key string, value interface{}, ttl time.Duration)
+
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// memoryCache is an in-process Cache implementation backed by a map.
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a value stored in the memoryCache with its expiration time.
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an in-process Cache that keeps values in a map until they expire or are deleted.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithCache(gormet.NewMemoryCache(), time.Minute))
+func NewMemoryCache() Cache {
+	return &memoryCache{
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the value stored under key if it exists and has not expired.
+func (c *memoryCache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.Delete(key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// Set stores the value under key, replacing any previous value.
+func (c *memoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	entry := cacheEntry{value: value}
+
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+}
+
+// Delete removes the value stored under key.
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// cacheKey builds the key under which the entity with the given id is cached.
+func (r *Repository[T]) cacheKey(id interface{}) string {
+	return fmt.Sprintf("%s:%v", r.schema.Table, id)
+}
+
+// invalidate removes the entity with the given id from the cache, if caching is enabled.
+func (r *Repository[T]) invalidate(id interface{}) {
+	if r.config.cache == nil {
+		return
+	}
+
+	key := r.cacheKey(id)
+
+	// Within a transaction, the entity is invalidated once it is committed, so a concurrent read
+	// cannot cache the previous value again after the invalidation.
+	r.afterCommit(func() { r.config.cache.Delete(key) })
+}
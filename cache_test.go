@@ -0,0 +1,138 @@
+package gormet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testCache struct {
+	gorm.Model
+	Name string `json:"name" gorm:"unique;not null;default:null"`
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Run("Set and get", func(t *testing.T) {
+		cache := NewMemoryCache()
+		cache.Set("key", "value", 0)
+
+		got, ok := cache.Get("key")
+		assert.True(t, ok)
+		assert.Equal(t, "value", got)
+	})
+
+	t.Run("Expired value", func(t *testing.T) {
+		cache := NewMemoryCache()
+		cache.Set("key", "value", time.Millisecond)
+
+		time.Sleep(5 * time.Millisecond)
+
+		got, ok := cache.Get("key")
+		assert.False(t, ok)
+		assert.Nil(t, got)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cache := NewMemoryCache()
+		cache.Set("key", "value", 0)
+		cache.Delete("key")
+
+		_, ok := cache.Get("key")
+		assert.False(t, ok)
+	})
+}
+
+func TestRepository_GetByIdCached(t *testing.T) {
+	db := getGormConnection(t, &testCache{})
+
+	cache := NewMemoryCache()
+	repo, err := New[testCache](db, WithCache(cache, time.Minute))
+	assert.Nil(t, err)
+
+	t.Run("Entity is cached", func(t *testing.T) {
+		entity := &testCache{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.Name, got.Name)
+
+		_, ok := cache.Get(repo.cacheKey(entity.ID))
+		assert.True(t, ok)
+
+		// Modifying the returned entity must not change the cached one.
+		got.Name = "changed"
+		again, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.Name, again.Name)
+	})
+
+	t.Run("Update invalidates the cache", func(t *testing.T) {
+		entity := &testCache{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		_, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		entity.Name = uuid.NewString()
+		assert.Nil(t, repo.Update(entity))
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.Name, got.Name)
+	})
+
+	t.Run("Transaction invalidates the cache on commit", func(t *testing.T) {
+		entity := &testCache{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		_, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		entity.Name = uuid.NewString()
+		err = repo.Transaction(func(tx *Repository[testCache]) error {
+			if err := tx.Update(entity); err != nil {
+				return err
+			}
+
+			// A concurrent read still sees the committed entity, which stays cached.
+			_, ok := cache.Get(repo.cacheKey(entity.ID))
+			assert.True(t, ok)
+
+			return errors.New("abort")
+		})
+		assert.Equal(t, "abort", err.Error())
+
+		_, ok := cache.Get(repo.cacheKey(entity.ID))
+		assert.True(t, ok)
+
+		assert.Nil(t, repo.Transaction(func(tx *Repository[testCache]) error {
+			return tx.Update(entity)
+		}))
+
+		_, ok = cache.Get(repo.cacheKey(entity.ID))
+		assert.False(t, ok)
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.Name, got.Name)
+	})
+
+	t.Run("Delete invalidates the cache", func(t *testing.T) {
+		entity := &testCache{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		_, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		assert.Nil(t, repo.DeleteById(entity.ID))
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, got)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,173 @@
+package gormet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Count returns the number of entities in the database matching the given criteria.
+//
+// Usage:
+// count, err := repo.Count(gormet.Eq[User]("active", true))
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - query: GORM query condition or Specification, nil to count all the entities.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - The number of matching entities.
+// - An error if the count operation encounters any issues.
+func (r *Repository[T]) Count(query interface{}, args ...interface{}) (int64, error) {
+	return r.CountWith(nil, query, args...)
+}
+
+// CountWith returns the number of matching entities as Count, customized by the given search options,
+// such as the named scopes returned by Scope.
+//
+// Usage:
+// count, err := repo.CountWith([]gormet.SearchOption{repo.Scope("published")}, "author_id = ?", authorId)
+//
+// Parameters:
+// - opts: The search options.
+// - query: GORM query condition or Specification, nil to count all the entities.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - The number of matching entities.
+// - An error if an option is invalid or if the count operation encounters any issues.
+func (r *Repository[T]) CountWith(opts []SearchOption, query interface{}, args ...interface{}) (int64, error) {
+	repo, args, err := r.withSearchOptions(args, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return repo.countRows(query, args...)
+}
+
+// searchPage retrieves a page of entities together with the total count of the search. The count is
+// taken from the count cache when possible, and otherwise computed serially or in parallel with the page
+// query, according to the configuration.
+func (r *Repository[T]) searchPage(offset int, limit int, query interface{}, args ...interface{}) ([]T, int64, error) {
+	key, count, cached := r.cachedCount(query, args...)
+
+	if cached {
+		entities, err := r.executeSearch(offset, limit, query, args...)
+		return entities, count, err
+	}
+
+	var entities []T
+	var err error
+
+	if r.config.concurrentCount && !r.inTx {
+		entities, count, err = r.searchConcurrently(offset, limit, query, args...)
+	} else {
+		entities, count, err = r.searchSerially(offset, limit, query, args...)
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if key != "" {
+		r.config.countCache.Set(key, count, r.config.countCacheTTL)
+	}
+
+	return entities, count, nil
+}
+
+// searchSerially runs the page query and then the count query.
+func (r *Repository[T]) searchSerially(offset int, limit int, query interface{}, args ...interface{}) ([]T, int64, error) {
+	entities, err := r.executeSearch(offset, limit, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := r.countRows(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entities, count, nil
+}
+
+// searchConcurrently runs the page query and the count query in parallel. The first error cancels
+// the other query and is the one returned.
+func (r *Repository[T]) searchConcurrently(offset int, limit int, query interface{}, args ...interface{}) ([]T, int64, error) {
+	ctx, cancel := context.WithCancel(r.db.Statement.Context)
+	defer cancel()
+
+	repo := r.withContext(ctx)
+
+	var entities []T
+	var count int64
+	var firstErr error
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		var err error
+		if entities, err = repo.executeSearch(offset, limit, query, args...); err != nil {
+			fail(err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		var err error
+		if count, err = repo.countRows(query, args...); err != nil {
+			fail(err)
+		}
+	}()
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+
+	return entities, count, nil
+}
+
+// withContext returns a copy of the repository issuing its statements with the given context.
+func (r *Repository[T]) withContext(ctx context.Context) *Repository[T] {
+	repo := *r
+	repo.db = r.db.WithContext(ctx)
+
+	return &repo
+}
+
+// cachedCount looks up the total count of the search in the count cache. It returns the cache key,
+// empty when the count must not be cached, the cached count and whether it was found. Searches
+// running inside a transaction bypass the cache, as they may see uncommitted changes.
+func (r *Repository[T]) cachedCount(query interface{}, args ...interface{}) (string, int64, bool) {
+	if r.config.countCache == nil || r.inTx {
+		return "", 0, false
+	}
+
+	key := fmt.Sprintf("%s:count:%s:%#v:%#v", r.schema.Table, r.scopeKey(), query, args)
+
+	if value, ok := r.config.countCache.Get(key); ok {
+		if count, ok := value.(int64); ok {
+			return key, count, true
+		}
+	}
+
+	return key, 0, false
+}
@@ -0,0 +1,141 @@
+package gormet
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SearchConcurrentCount(t *testing.T) {
+	db := getGormConnection(t, &testSearch{})
+
+	repo, err := New[testSearch](db, WithPageSize(10), WithConcurrentCount())
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	createMany(repo, 25, group)
+
+	t.Run("Same result as serial search", func(t *testing.T) {
+		serial, err := New[testSearch](db, WithPageSize(10))
+		assert.Nil(t, err)
+
+		want, err := serial.Search(2, "filter = ?", group)
+		assert.Nil(t, err)
+
+		got, err := repo.Search(2, "filter = ?", group)
+		assert.Nil(t, err)
+
+		assert.Equal(t, want.Response, got.Response)
+		assert.Equal(t, int64(25), got.Response.TotalCount)
+		assert.Len(t, got.Response.Entities, 10)
+	})
+
+	t.Run("Request Error", func(t *testing.T) {
+		resp, err := repo.Search(1, "this_field_generate_error = ?", group)
+
+		assert.NotNil(t, err)
+		assert.Empty(t, resp.Response.Entities)
+	})
+
+	t.Run("Inside a transaction", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testSearch]) error {
+			resp, err := tx.Search(1, "filter = ?", group)
+			assert.Equal(t, int64(25), resp.Response.TotalCount)
+
+			return err
+		})
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestRepository_SearchCountCache(t *testing.T) {
+	db := getGormConnection(t, &testSearch{})
+
+	cache := NewMemoryCache()
+	repo, err := New[testSearch](db, WithPageSize(10), WithCountCache(cache, time.Minute))
+	assert.Nil(t, err)
+
+	t.Run("Count reused while paging", func(t *testing.T) {
+		group := uuid.NewString()
+		createMany(repo, 15, group)
+
+		resp, err := repo.Search(1, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(15), resp.Response.TotalCount)
+
+		createMany(repo, 10, group)
+
+		// The count is cached for the criteria, while the page itself is always fresh.
+		resp, err = repo.Search(2, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(15), resp.Response.TotalCount)
+		assert.Len(t, resp.Response.Entities, 10)
+	})
+
+	t.Run("Count per criteria", func(t *testing.T) {
+		first, second := uuid.NewString(), uuid.NewString()
+		createMany(repo, 3, first)
+		createMany(repo, 5, second)
+
+		resp, err := repo.Search(1, "filter = ?", first)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), resp.Response.TotalCount)
+
+		resp, err = repo.Search(1, "filter = ?", second)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), resp.Response.TotalCount)
+	})
+
+	t.Run("Expired count", func(t *testing.T) {
+		short, err := New[testSearch](db, WithPageSize(10), WithCountCache(NewMemoryCache(), 10*time.Millisecond))
+		assert.Nil(t, err)
+
+		group := uuid.NewString()
+		createMany(repo, 2, group)
+
+		resp, err := short.Search(1, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), resp.Response.TotalCount)
+
+		createMany(repo, 1, group)
+		time.Sleep(20 * time.Millisecond)
+
+		resp, err = short.Search(1, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), resp.Response.TotalCount)
+	})
+
+	t.Run("Bypassed inside a transaction", func(t *testing.T) {
+		group := uuid.NewString()
+		createMany(repo, 2, group)
+
+		_, err := repo.Search(1, "filter = ?", group)
+		assert.Nil(t, err)
+
+		err = repo.Transaction(func(tx *Repository[testSearch]) error {
+			name := uuid.NewString()
+			if err := tx.Create(&testSearch{Name: name, Email: fmt.Sprintf("%s@email.com", name), Filter: group}); err != nil {
+				return err
+			}
+
+			resp, err := tx.Search(1, "filter = ?", group)
+			assert.Equal(t, int64(3), resp.Response.TotalCount)
+
+			return err
+		})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := New[testSearch](db, WithCountCache(nil, time.Minute))
+		assert.NotNil(t, err)
+
+		_, err = New[testSearch](db, WithCountCache(cache, 0))
+		assert.NotNil(t, err)
+	})
+}
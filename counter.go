@@ -0,0 +1,210 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// CounterOption customizes a single call to Increment or Decrement.
+type CounterOption func(*counterConfig)
+
+// counterConfig holds the settings assembled from the counter options of a call.
+type counterConfig struct {
+	lowerBound *int64 // Minimum value of the column after the change, nil for no bound.
+}
+
+// LowerBound makes Increment and Decrement fail with ErrInsufficient, leaving the column unchanged,
+// when its new value would be lower than min.
+//
+// Usage:
+// stock, err := repo.Decrement(productID, "stock", 2, gormet.LowerBound(0))
+func LowerBound(min int64) CounterOption {
+	return func(c *counterConfig) {
+		c.lowerBound = &min
+	}
+}
+
+// Increment atomically adds delta to an integer column of the entity with the given ID and returns
+// the new value of the column.
+//
+// The change is made by a single UPDATE statement computing the new value in the database, so
+// concurrent increments are never lost, unlike reading the entity, modifying it and calling Update.
+// The new value is read back by the same statement with RETURNING where the dialect supports it.
+// The update time of the entity is refreshed, the hooks of the repository are not invoked and the
+// cached entity is invalidated.
+//
+// Usage:
+// views, err := repo.Increment(articleID, "views", 1)
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - id: The ID of the entity to change. It should not be nil.
+// - column: The integer column to change, by column or field name.
+// - delta: The value added to the column, negative to decrease it. It should not be zero.
+// - opts: Optional counter options, such as LowerBound.
+//
+// Returns:
+// - The new value of the column.
+// - gorm.ErrRecordNotFound if no entity has the ID, ErrInsufficient if the lower bound would be
+// crossed, or an error if the column is not an integer or the update operation encounters any issues.
+func (r *Repository[T]) Increment(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error) {
+	if id == nil {
+		return 0, errors.New("the id should not be nil")
+	}
+
+	field, cfg, err := counterField(r.schema, column, delta, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var value int64
+	condition := fmt.Sprintf("%s = ?", r.pkName)
+	expr := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	err = r.transaction(func(tx *gorm.DB) error {
+		entity := new(T)
+		update := tx.Model(entity).Where(condition, id)
+
+		if cfg.lowerBound != nil {
+			update = update.Where("? + ? >= ?", expr, delta, *cfg.lowerBound)
+		}
+
+		returning := slices.Contains(tx.Callback().Update().Clauses, "RETURNING")
+		if returning {
+			update = update.Clauses(clause.Returning{Columns: []clause.Column{{Name: field.DBName}}})
+		}
+
+		result := update.Updates(map[string]interface{}{field.DBName: gorm.Expr("? + ?", expr, delta)})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// As delta is not zero, a row matching the ID and the bound is always reported as affected.
+		if result.RowsAffected > 0 && returning {
+			value = counterValue(field, reflect.ValueOf(entity).Elem())
+			return nil
+		}
+
+		err := tx.Model(new(T)).Where(condition, id).Select(field.DBName).Take(&value).Error
+		if err != nil || result.RowsAffected > 0 {
+			return err
+		}
+
+		return ErrInsufficient
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	r.invalidate(id)
+
+	return value, nil
+}
+
+// Decrement atomically subtracts delta from an integer column of the entity with the given ID and
+// returns the new value of the column. See Increment.
+//
+// Usage:
+// stock, err := repo.Decrement(productID, "stock", 2, gormet.LowerBound(0))
+//
+//	if errors.Is(err, gormet.ErrInsufficient) {
+//	    // Handle out of stock
+//	}
+//
+// Parameters:
+// - id: The ID of the entity to change. It should not be nil.
+// - column: The integer column to change, by column or field name.
+// - delta: The value subtracted from the column. It should not be zero.
+// - opts: Optional counter options, such as LowerBound.
+//
+// Returns:
+// - The new value of the column.
+// - gorm.ErrRecordNotFound if no entity has the ID, ErrInsufficient if the lower bound would be
+// crossed, or an error if the column is not an integer or the update operation encounters any issues.
+func (r *Repository[T]) Decrement(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error) {
+	return r.Increment(id, column, -delta, opts...)
+}
+
+// Increment atomically adds delta to an integer column of the entity with the given ID and returns the new value.
+func (m *MemoryRepository[T]) Increment(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error) {
+	if id == nil {
+		return 0, errors.New("the id should not be nil")
+	}
+
+	field, cfg, err := counterField(m.schema, column, delta, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	idx := m.indexOf(id)
+	if idx < 0 || !m.visible(reflect.ValueOf(&m.store.entities[idx]).Elem()) {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	rv := reflect.ValueOf(&m.store.entities[idx]).Elem()
+	value := counterValue(field, rv) + delta
+
+	if cfg.lowerBound != nil && value < *cfg.lowerBound {
+		return 0, ErrInsufficient
+	}
+
+	if err := field.Set(context.Background(), rv, value); err != nil {
+		return 0, err
+	}
+
+	m.setAutoTime(rv, false)
+
+	return value, nil
+}
+
+// Decrement atomically subtracts delta from an integer column of the entity with the given ID and returns the new value.
+func (m *MemoryRepository[T]) Decrement(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error) {
+	return m.Increment(id, column, -delta, opts...)
+}
+
+// counterField resolves the integer column changed by Increment and applies the counter options.
+func counterField(sch *schema.Schema, column string, delta int64, opts []CounterOption) (*schema.Field, counterConfig, error) {
+	var cfg counterConfig
+
+	// A zero delta changes nothing, which some databases such as MySQL report as no affected row.
+	if delta == 0 {
+		return nil, cfg, errors.New("the delta should not be zero")
+	}
+
+	field := lookUpDBField(sch, column)
+	if field == nil {
+		return nil, cfg, fmt.Errorf("unknown column: %q", column)
+	}
+
+	if field.DataType != schema.Int && field.DataType != schema.Uint {
+		return nil, cfg, fmt.Errorf("the column %q should be an integer", column)
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	return field, cfg, nil
+}
+
+// counterValue returns the value of the integer column of the entity.
+func counterValue(field *schema.Field, entity reflect.Value) int64 {
+	value, _ := normalizeValue(fieldValue(field, entity)).(int64)
+	return value
+}
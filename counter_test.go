@@ -0,0 +1,129 @@
+package gormet
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testCounter struct {
+	gorm.Model
+	Name  string  `json:"name"`
+	Stock int     `json:"stock"`
+	Views uint    `json:"views"`
+	Price float64 `json:"price"`
+}
+
+func TestIncrement(t *testing.T) {
+	db := getGormConnection(t, &testCounter{})
+
+	repo, err := New[testCounter](db, WithCache(NewMemoryCache(), time.Minute))
+	assert.Nil(t, err)
+
+	memory, err := NewMemory[testCounter]()
+	assert.Nil(t, err)
+
+	for name, api := range map[string]RepositoryAPI[testCounter]{"database": repo, "memory": memory} {
+		t.Run("Increment and decrement in "+name, func(t *testing.T) {
+			entity := &testCounter{Name: uuid.NewString(), Stock: 5}
+			assert.Nil(t, api.Create(entity))
+
+			views, err := api.Increment(entity.ID, "views", 3)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), views)
+
+			stock, err := api.Decrement(entity.ID, "Stock", 2)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), stock)
+
+			stored, err := api.GetById(entity.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, uint(3), stored.Views)
+			assert.Equal(t, 3, stored.Stock)
+			assert.True(t, stored.UpdatedAt.After(entity.UpdatedAt) || stored.UpdatedAt.Equal(entity.UpdatedAt))
+		})
+
+		t.Run("Lower bound in "+name, func(t *testing.T) {
+			entity := &testCounter{Name: uuid.NewString(), Stock: 2}
+			assert.Nil(t, api.Create(entity))
+
+			stock, err := api.Decrement(entity.ID, "stock", 2, LowerBound(0))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), stock)
+
+			_, err = api.Decrement(entity.ID, "stock", 1, LowerBound(0))
+			assert.True(t, errors.Is(err, ErrInsufficient))
+
+			stored, err := api.GetById(entity.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, stored.Stock)
+		})
+
+		t.Run("Errors in "+name, func(t *testing.T) {
+			_, err := api.Increment(nil, "stock", 1)
+			assert.Equal(t, "the id should not be nil", err.Error())
+
+			_, err = api.Increment(uint(999999), "stock", 1)
+			assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+			_, err = api.Increment(uint(1), "unknown", 1)
+			assert.Equal(t, `unknown column: "unknown"`, err.Error())
+
+			_, err = api.Increment(uint(1), "price", 1)
+			assert.Equal(t, `the column "price" should be an integer`, err.Error())
+
+			_, err = api.Decrement(uint(1), "stock", 0)
+			assert.Equal(t, "the delta should not be zero", err.Error())
+		})
+	}
+
+	t.Run("Concurrent increments", func(t *testing.T) {
+		policy := DefaultRetryPolicy()
+		policy.MaxAttempts = 50
+		policy.InitialBackoff = time.Millisecond
+
+		concurrent, err := New[testCounter](db, WithRetry(policy))
+		assert.Nil(t, err)
+
+		entity := &testCounter{Name: uuid.NewString()}
+		assert.Nil(t, concurrent.Create(entity))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := concurrent.Increment(entity.ID, "views", 1)
+				assert.Nil(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		stored, err := concurrent.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, uint(10), stored.Views)
+	})
+
+	t.Run("Cache invalidated", func(t *testing.T) {
+		entity := &testCounter{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		_, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		_, err = repo.Increment(entity.ID, "views", 7)
+		assert.Nil(t, err)
+
+		stored, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, uint(7), stored.Views)
+	})
+}
@@ -3,14 +3,16 @@ package gormet
 import (
 	"errors"
 	"fmt"
+
+	"gorm.io/gorm"
 )
 
 // DeleteById removes an entity from the database using its ID.
 //
 // This method takes an ID as an argument, ensures it is not nil, and then uses GORM's Delete method
 // to delete the corresponding record from the database. The repository's primary key name is used
-// in the query condition. If the operation is successful, it returns nil. If the operation fails,
-// an error is returned, which could be due to database connectivity issues or other constraints.
+// in the query condition and the record is removed according to the configured SoftDeleteStrategy.
+// If the operation is successful, it returns nil. If the operation fails, an error is returned, which could be due to database connectivity issues or other constraints.
 //
 // Usage:
 // err := repo.DeleteById(id)
@@ -31,24 +33,37 @@ func (r *Repository[T]) DeleteById(id interface{}) error {
 		return errors.New("the ID should not be nil")
 	}
 
-	condition := fmt.Sprintf("%s = ?", r.pkName)
-	deleteResult := r.db.Delete(new(T), condition, id)
+	entity := new(T)
 
-	if deleteResult.Error != nil {
-		return deleteResult.Error
+	// Hooks receive the entity being deleted, so its primary key must be set.
+	if len(r.config.hooks) > 0 {
+		var err error
+		if entity, err = r.entityWithId(id); err != nil {
+			return fmt.Errorf("invalid id: %v", err)
+		}
 	}
 
-	if deleteResult.RowsAffected == 0 {
-		return fmt.Errorf("no register found")
+	condition := fmt.Sprintf("%s = ?", r.pkName)
+
+	err := r.write(opDelete, entity, func(tx *gorm.DB) error {
+		return checkDeleteResult(r.deleteSession(tx).Delete(new(T), condition, id))
+	})
+
+	if err != nil {
+		return err
 	}
 
+	r.invalidate(id)
+	r.untrack(id)
+
 	return nil
 }
 
 // Delete removes an entity from the database.
 //
 // This method takes an entity as an argument, ensures it is not nil, and then uses GORM's Delete method
-// to delete the corresponding record from the database. If the operation is successful, it returns nil.
+// to delete the corresponding record from the database according to the configured SoftDeleteStrategy.
+// If the operation is successful, it returns nil.
 // If the operation fails, an error is returned, which could be due to database connectivity issues or other constraints.
 //
 // Usage:
@@ -70,8 +85,31 @@ func (r *Repository[T]) Delete(entity *T) error {
 		return errors.New("the entity should not be nil")
 	}
 
-	deleteResult := r.db.Delete(entity)
+	err := r.write(opDelete, entity, func(tx *gorm.DB) error {
+		return checkDeleteResult(r.deleteSession(tx).Delete(entity))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.invalidate(r.primaryKeyValue(entity))
+	r.untrack(r.primaryKeyValue(entity))
+
+	return nil
+}
+
+// deleteSession applies the soft delete strategy to the given connection.
+func (r *Repository[T]) deleteSession(tx *gorm.DB) *gorm.DB {
+	if r.config.softDelete == SoftDeleteNever {
+		return tx.Unscoped()
+	}
+
+	return tx
+}
 
+// checkDeleteResult converts the result of a delete statement into an error.
+func checkDeleteResult(deleteResult *gorm.DB) error {
 	if deleteResult.Error != nil {
 		return deleteResult.Error
 	}
@@ -0,0 +1,49 @@
+package gormet
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInsufficient is returned by Increment and Decrement when the new value of the column would
+// fall below the lower bound passed with LowerBound, such as a stock level going negative.
+var ErrInsufficient = errors.New("insufficient value")
+
+// IsDuplicateKey reports whether the error is a violation of a primary key or unique constraint.
+// It recognizes gorm.ErrDuplicatedKey, returned when the connection is opened with TranslateError,
+// and the raw errors of SQLite, PostgreSQL, MySQL and SQL Server.
+//
+// Usage:
+//
+//	if err := repo.Create(&user); gormet.IsDuplicateKey(err) {
+//		// Handle duplicated user
+//	}
+func IsDuplicateKey(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+
+	for _, pattern := range []string{
+		"unique constraint failed",            // SQLite
+		"primary key constraint failed",       // SQLite
+		"duplicate key value violates unique", // PostgreSQL
+		"sqlstate 23505",                      // PostgreSQL
+		"error 1062",                          // MySQL
+		"duplicate entry",                     // MySQL
+		"cannot insert duplicate key",         // SQL Server
+	} {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: gorm.ErrDuplicatedKey, want: true},
+		{err: fmt.Errorf("wrapped: %w", gorm.ErrDuplicatedKey), want: true},
+		{err: errors.New("UNIQUE constraint failed: users.name"), want: true},
+		{err: errors.New(`ERROR: duplicate key value violates unique constraint "users_name_key" (SQLSTATE 23505)`), want: true},
+		{err: errors.New("Error 1062 (23000): Duplicate entry 'john' for key 'name'"), want: true},
+		{err: errors.New("NOT NULL constraint failed: users.name"), want: false},
+		{err: gorm.ErrRecordNotFound, want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsDuplicateKey(tt.err), "%v", tt.err)
+	}
+}
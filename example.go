@@ -0,0 +1,184 @@
+package gormet
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// StringMatch defines how the string fields of an example are compared by FindByExample.
+type StringMatch int
+
+const (
+	MatchExact      StringMatch = iota // The column is equal to the value.
+	MatchContains                      // The column contains the value.
+	MatchStartsWith                    // The column starts with the value.
+	MatchEndsWith                      // The column ends with the value.
+)
+
+// ExampleMatcher configures how FindByExample turns an example entity into criteria.
+//
+// By default, as GORM does with struct conditions, every non-zero field of the example is compared
+// for equality, and the zero-valued fields are ignored. The matcher restricts the fields considered,
+// includes zero values explicitly, such as Active=false or an empty string, and relaxes the comparison
+// of strings. Fields are referenced by their struct field name or column name.
+type ExampleMatcher struct {
+	Include    []string    // Fields considered, all of them when empty.
+	Exclude    []string    // Fields ignored.
+	ZeroValues []string    // Fields compared even when zero; a nil pointer matches NULL.
+	Strings    StringMatch // How the string fields are compared.
+	IgnoreCase bool        // Whether the string fields are compared case-insensitively.
+}
+
+// FindByExample performs a paginated search for the entities matching the fields of an example entity.
+//
+// Unlike Get, which ignores every zero-valued field, the matcher decides which fields are compared,
+// whether zero values are included and how strings are matched. The soft delete field of the model is
+// never compared. The results are paginated like Search and the search options are supported.
+//
+// Usage:
+//
+//	example := User{Name: "jo", Active: false}
+//	matcher := gormet.ExampleMatcher{
+//		ZeroValues: []string{"Active"},
+//		Strings:    gormet.MatchStartsWith,
+//		IgnoreCase: true,
+//	}
+//
+//	pagination, err := repo.FindByExample(1, example, matcher)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - page: The page number for pagination (starting from 1).
+// - example: An entity holding the values to match.
+// - matcher: The configuration of the matching.
+// - opts: Optional search options, such as SearchPageSize or SearchSort.
+//
+// Returns:
+// - A structure containing the paginated search results, including entities, total count, and pagination details.
+// - An error if the matcher references an unknown field, or if the search operation encounters any issues.
+func (r *Repository[T]) FindByExample(page uint, example T, matcher ExampleMatcher, opts ...SearchOption) (Pagination[T], error) {
+	spec, err := exampleSpecification(r.schema, example, matcher)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	return r.SearchWith(page, opts, spec)
+}
+
+// FindByExample performs a paginated search for the entities matching the fields of an example entity.
+func (m *MemoryRepository[T]) FindByExample(page uint, example T, matcher ExampleMatcher, opts ...SearchOption) (Pagination[T], error) {
+	spec, err := exampleSpecification(m.schema, example, matcher)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	return m.SearchWith(page, opts, spec)
+}
+
+// exampleSpecification builds the specification matching the example according to the matcher.
+func exampleSpecification[T any](sch *schema.Schema, example T, matcher ExampleMatcher) (Specification[T], error) {
+	include, err := matcherFields(sch, matcher.Include)
+	if err != nil {
+		return Specification[T]{}, err
+	}
+
+	exclude, err := matcherFields(sch, matcher.Exclude)
+	if err != nil {
+		return Specification[T]{}, err
+	}
+
+	zeroValues, err := matcherFields(sch, matcher.ZeroValues)
+	if err != nil {
+		return Specification[T]{}, err
+	}
+
+	if matcher.Strings < MatchExact || matcher.Strings > MatchEndsWith {
+		return Specification[T]{}, fmt.Errorf("invalid example matcher: unknown string match: %d", matcher.Strings)
+	}
+
+	deletedAt := softDeleteField(sch)
+	rv := reflect.ValueOf(&example).Elem()
+	specs := make([]Specification[T], 0)
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field == deletedAt || exclude[field] || (len(include) > 0 && !include[field]) {
+			continue
+		}
+
+		value, zero := field.ValueOf(context.Background(), rv)
+		if zero && !zeroValues[field] {
+			continue
+		}
+
+		if isNilValue(value) {
+			specs = append(specs, IsNull[T](field.DBName))
+			continue
+		}
+
+		specs = append(specs, exampleCondition[T](field, value, matcher))
+	}
+
+	if len(specs) == 0 {
+		return Specification[T]{op: "ALL"}, nil
+	}
+
+	return And(specs...), nil
+}
+
+// exampleCondition builds the condition of a single field of the example.
+func exampleCondition[T any](field *schema.Field, value interface{}, matcher ExampleMatcher) Specification[T] {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.String || (matcher.Strings == MatchExact && !matcher.IgnoreCase) {
+		return Eq[T](field.DBName, value)
+	}
+
+	op := map[StringMatch]string{
+		MatchExact:      "=",
+		MatchContains:   "CONTAINS",
+		MatchStartsWith: "STARTS WITH",
+		MatchEndsWith:   "ENDS WITH",
+	}[matcher.Strings]
+
+	return Specification[T]{op: op, column: field.DBName, values: []interface{}{rv.String()}, fold: matcher.IgnoreCase}
+}
+
+// matcherFields resolves the field names of a matcher against the schema.
+func matcherFields(sch *schema.Schema, names []string) (map[*schema.Field]bool, error) {
+	fields := make(map[*schema.Field]bool, len(names))
+
+	for _, name := range names {
+		field := lookUpDBField(sch, name)
+		if field == nil {
+			return nil, fmt.Errorf("invalid example matcher: unknown field: %q", name)
+		}
+
+		fields[field] = true
+	}
+
+	return fields, nil
+}
+
+// isNilValue reports whether the value is nil or a nil pointer.
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern with likeEscape, a character with no special
+// meaning in the string literals of any dialect.
+func escapeLike(text string) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").Replace(text)
+}
+
+// likeEscape is the escape character of the patterns built by escapeLike.
+const likeEscape = "!"
@@ -0,0 +1,104 @@
+package gormet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testExample struct {
+	gorm.Model
+	Name   string  `json:"name"`
+	Group  string  `json:"group"`
+	Active bool    `json:"active"`
+	Note   *string `json:"note"`
+}
+
+func TestFindByExample(t *testing.T) {
+	db := getGormConnection(t, &testExample{})
+
+	repo, err := New[testExample](db, WithDefaultSort("name ASC"), WithPageSize(10))
+	assert.Nil(t, err)
+
+	memory, err := NewMemory[testExample](WithDefaultSort("name ASC"), WithPageSize(10))
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	note := "note"
+
+	for _, api := range []RepositoryAPI[testExample]{repo, memory} {
+		for _, entity := range []testExample{
+			{Name: "Alice", Group: group, Active: true, Note: &note},
+			{Name: "alfred", Group: group, Active: false},
+			{Name: "Bob", Group: group, Active: false, Note: &note},
+			{Name: "", Group: group, Active: true},
+			{Name: "50%_off", Group: group, Active: true},
+		} {
+			entity := entity
+			assert.Nil(t, api.Create(&entity))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		example testExample
+		matcher ExampleMatcher
+		names   []string
+	}{
+		{name: "Zero values ignored", example: testExample{Group: group, Active: false}, names: []string{"", "50%_off", "Alice", "Bob", "alfred"}},
+		{name: "Zero value included", example: testExample{Group: group}, matcher: ExampleMatcher{ZeroValues: []string{"Active"}}, names: []string{"Bob", "alfred"}},
+		{name: "Empty string included", example: testExample{Group: group}, matcher: ExampleMatcher{ZeroValues: []string{"name"}}, names: []string{""}},
+		{name: "Nil pointer included", example: testExample{Group: group, Active: true}, matcher: ExampleMatcher{ZeroValues: []string{"Note"}}, names: []string{"", "50%_off"}},
+		{name: "Include", example: testExample{Name: "Bob", Group: group}, matcher: ExampleMatcher{Include: []string{"Group"}}, names: []string{"", "50%_off", "Alice", "Bob", "alfred"}},
+		{name: "Exclude", example: testExample{Name: "Bob", Group: group, Active: true}, matcher: ExampleMatcher{Exclude: []string{"active"}}, names: []string{"Bob"}},
+		{name: "Exact ignoring case", example: testExample{Name: "ALICE", Group: group}, matcher: ExampleMatcher{IgnoreCase: true}, names: []string{"Alice"}},
+		{name: "Starts with ignoring case", example: testExample{Name: "al", Group: group}, matcher: ExampleMatcher{Strings: MatchStartsWith, IgnoreCase: true}, names: []string{"Alice", "alfred"}},
+		{name: "Ends with", example: testExample{Name: "ed", Group: group}, matcher: ExampleMatcher{Strings: MatchEndsWith}, names: []string{"alfred"}},
+		{name: "Contains wildcards", example: testExample{Name: "%_", Group: group}, matcher: ExampleMatcher{Strings: MatchContains}, names: []string{"50%_off"}},
+	}
+
+	for _, tt := range tests {
+		for name, api := range map[string]RepositoryAPI[testExample]{"database": repo, "memory": memory} {
+			t.Run(tt.name+" in "+name, func(t *testing.T) {
+				resp, err := api.FindByExample(1, tt.example, tt.matcher)
+				assert.Nil(t, err)
+
+				names := make([]string, len(resp.Response.Entities))
+				for i, entity := range resp.Response.Entities {
+					names[i] = entity.Name
+				}
+
+				assert.Equal(t, tt.names, names)
+				assert.Equal(t, int64(len(tt.names)), resp.Response.TotalCount)
+			})
+		}
+	}
+
+	t.Run("Paginated with search options", func(t *testing.T) {
+		resp, err := repo.FindByExample(2, testExample{Group: group}, ExampleMatcher{}, SearchPageSize(2))
+
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 2)
+		assert.Equal(t, int64(5), resp.Response.TotalCount)
+		assert.Equal(t, int64(3), resp.Response.TotalPages)
+	})
+
+	t.Run("Empty example", func(t *testing.T) {
+		resp, err := memory.FindByExample(1, testExample{}, ExampleMatcher{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), resp.Response.TotalCount)
+
+		_, err = repo.FindByExample(1, testExample{}, ExampleMatcher{})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		_, err := repo.FindByExample(1, testExample{}, ExampleMatcher{Include: []string{"unknown"}})
+		assert.Equal(t, `invalid example matcher: unknown field: "unknown"`, err.Error())
+
+		_, err = memory.FindByExample(1, testExample{}, ExampleMatcher{Strings: StringMatch(42)})
+		assert.NotNil(t, err)
+	})
+}
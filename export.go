@@ -0,0 +1,224 @@
+package gormet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Format is the encoding used by Export and Import.
+type Format string
+
+const (
+	FormatJSON   Format = "json"   // A JSON array of entities, encoded with their json tags.
+	FormatNDJSON Format = "ndjson" // One JSON entity per line, encoded with their json tags.
+	FormatCSV    Format = "csv"    // A header with the database column names followed by one entity per row.
+)
+
+// exportBatchSize is the number of entities loaded from the database at once by Export.
+const exportBatchSize = 500
+
+// entityEncoder writes entities in a given format.
+type entityEncoder[T any] interface {
+	encode(entity *T) error
+	close() error
+}
+
+// Export streams the entities matching the given criteria to the writer in the given format.
+//
+// The entities are loaded from the database in batches ordered by primary key, so large tables can
+// be exported without being kept in memory. JSON and NDJSON use the json tags of the model, while
+// CSV uses the database column names from the GORM schema, with times formatted as RFC 3339.
+//
+// Usage:
+// err := repo.Export(file, gormet.FormatCSV, "active = ?", true)
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - w: The writer receiving the encoded entities.
+// - format: The encoding of the entities.
+// - query: GORM query condition, nil to export all the entities.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - nil if all the matching entities were written.
+// - An error if the format is unknown, or if the query or the writer fails.
+func (r *Repository[T]) Export(w io.Writer, format Format, query interface{}, args ...interface{}) error {
+	var encoder entityEncoder[T]
+
+	switch format {
+	case FormatJSON:
+		encoder = &jsonEncoder[T]{w: w}
+	case FormatNDJSON:
+		encoder = &ndjsonEncoder[T]{encoder: json.NewEncoder(w)}
+	case FormatCSV:
+		encoder = &csvEncoder[T]{writer: csv.NewWriter(w), fields: csvFields(r.schema)}
+	default:
+		return fmt.Errorf("unknown format: %q", format)
+	}
+
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return err
+	}
+
+	tx := r.scoped(r.db.Model(new(T)))
+	if query != nil {
+		tx = tx.Where(query, args...)
+	}
+
+	var batch []T
+	result := tx.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := encoder.encode(&batch[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return encoder.close()
+}
+
+// jsonEncoder writes the entities as a JSON array.
+type jsonEncoder[T any] struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder[T]) encode(entity *T) error {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if e.count == 0 {
+		separator = "["
+	}
+
+	e.count++
+
+	if _, err = io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonEncoder[T]) close() error {
+	if e.count == 0 {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// ndjsonEncoder writes one JSON entity per line.
+type ndjsonEncoder[T any] struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder[T]) encode(entity *T) error {
+	return e.encoder.Encode(entity)
+}
+
+func (e *ndjsonEncoder[T]) close() error {
+	return nil
+}
+
+// csvEncoder writes a header with the column names followed by one entity per row.
+type csvEncoder[T any] struct {
+	writer *csv.Writer
+	fields []*schema.Field
+	header bool
+}
+
+func (e *csvEncoder[T]) encode(entity *T) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(entity).Elem()
+	record := make([]string, len(e.fields))
+
+	for i, field := range e.fields {
+		record[i] = formatCSVValue(fieldValue(field, rv))
+	}
+
+	return e.writer.Write(record)
+}
+
+func (e *csvEncoder[T]) close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// writeHeader writes the column names, once.
+func (e *csvEncoder[T]) writeHeader() error {
+	if e.header {
+		return nil
+	}
+
+	e.header = true
+
+	header := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		header[i] = field.DBName
+	}
+
+	return e.writer.Write(header)
+}
+
+// csvFields returns the fields of the schema mapped to database columns.
+func csvFields(sch *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+
+	for _, field := range sch.Fields {
+		if field.DBName != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// formatCSVValue converts a field value into its CSV representation. NULL values become empty strings.
+func formatCSVValue(value interface{}) string {
+	switch v := normalizeValue(value).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,94 @@
+package gormet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testExport struct {
+	gorm.Model
+	Name   string `json:"name" gorm:"unique;not null;default:null"`
+	Group  string `json:"group"`
+	Active bool   `json:"active"`
+}
+
+func createExport(t *testing.T, repo *Repository[testExport], group string, count int) []*testExport {
+	var entities []*testExport
+
+	for n := 0; n < count; n++ {
+		entity := &testExport{Name: uuid.NewString(), Group: group, Active: n%2 == 0}
+		assert.Nil(t, repo.Create(entity))
+
+		entities = append(entities, entity)
+	}
+
+	return entities
+}
+
+func TestRepository_Export(t *testing.T) {
+	db := getGormConnection(t, &testExport{})
+
+	repo, err := New[testExport](db)
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	created := createExport(t, repo, group, 3)
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, repo.Export(&buf, FormatJSON, "`group` = ?", group))
+
+		var entities []testExport
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &entities))
+		assert.Len(t, entities, 3)
+		assert.Equal(t, created[0].Name, entities[0].Name)
+	})
+
+	t.Run("Empty JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, repo.Export(&buf, FormatJSON, "`group` = ?", uuid.NewString()))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, repo.Export(&buf, FormatNDJSON, "`group` = ?", group))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 3)
+
+		var entity testExport
+		assert.Nil(t, json.Unmarshal([]byte(lines[2]), &entity))
+		assert.Equal(t, created[2].Name, entity.Name)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, repo.Export(&buf, FormatCSV, "`group` = ?", group))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, records, 4)
+		assert.Equal(t, []string{"id", "created_at", "updated_at", "deleted_at", "name", "group", "active"}, records[0])
+		assert.Equal(t, created[0].Name, records[1][4])
+		assert.Equal(t, "", records[1][3])
+		assert.Equal(t, "1", records[1][6])
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		err := repo.Export(&bytes.Buffer{}, Format("xml"), nil)
+		assert.Equal(t, `unknown format: "xml"`, err.Error())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		err := repo.Export(&bytes.Buffer{}, FormatJSON, "this_field_generate_error = ?", 1)
+		assert.NotNil(t, err)
+	})
+}
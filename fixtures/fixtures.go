@@ -0,0 +1,314 @@
+// Package fixtures loads YAML or JSON fixture files into gormet repositories, for integration tests and seeds.
+//
+// A fixture file is a map of fixture sets to labeled entities. Each set is associated with a
+// repository through Register, and each entity is a map of column (or field) names to values:
+//
+//	users:
+//	  alice:
+//	    name: Alice
+//	    token: '{{ uuid }}'
+//	    created_at: '{{ ago "48h" }}'
+//	posts:
+//	  welcome:
+//	    title: Welcome
+//	    user_id: '{{ ref "users.alice" }}'
+//
+// String values are Go templates with the functions uuid, now, ago, fromNow and ref. The ref
+// function returns the primary key of a fixture loaded before, identified by "set.label", so
+// sets must be listed after the sets they reference. Since JSON is valid YAML, JSON files are
+// supported as well.
+//
+// Usage:
+//
+//	loader := fixtures.New(db)
+//	fixtures.Register(loader, "users", userRepo)
+//	fixtures.Register(loader, "posts", postRepo)
+//
+//	if err := loader.Reload("testdata/fixtures.yml"); err != nil {
+//		// Handle error
+//	}
+//
+//	alice, err := fixtures.Entity[User](loader, "users.alice")
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gsdenys/gormet"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Loader loads fixture files into the registered repositories.
+type Loader struct {
+	db       *gorm.DB
+	sets     map[string]target      // Registered fixture sets by name.
+	order    []string               // Names of the sets in registration order.
+	entities map[string]interface{} // Loaded entities by "set.label".
+	refs     map[string]interface{} // Primary keys of the loaded entities by "set.label".
+}
+
+// target is a fixture set bound to a repository.
+type target interface {
+	// create builds an entity from the values and stores it, returning the entity and its primary key.
+	create(values map[string]interface{}, render func(string) (string, error)) (interface{}, interface{}, error)
+
+	// truncate removes all the rows of the table, including the soft deleted ones.
+	truncate(db *gorm.DB) error
+}
+
+// repositoryTarget is a fixture set stored through a gormet repository.
+type repositoryTarget[T any] struct {
+	repo   *gormet.Repository[T]
+	schema *schema.Schema
+}
+
+// New creates a Loader using the given database to parse the models and truncate the tables.
+func New(db *gorm.DB) *Loader {
+	return &Loader{
+		db:       db,
+		sets:     make(map[string]target),
+		entities: make(map[string]interface{}),
+		refs:     make(map[string]interface{}),
+	}
+}
+
+// Register associates a fixture set with a repository. The sets are truncated in the reverse
+// order of registration, so sets referenced by others should be registered first.
+//
+// Usage:
+// err := fixtures.Register(loader, "users", userRepo)
+func Register[T any](l *Loader, name string, repo *gormet.Repository[T]) error {
+	if name == "" {
+		return errors.New("the fixture set name should not be empty")
+	}
+
+	if repo == nil {
+		return errors.New("the repository should not be nil")
+	}
+
+	if _, ok := l.sets[name]; ok {
+		return fmt.Errorf("the fixture set %s is already registered", name)
+	}
+
+	stmt := &gorm.Statement{DB: l.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return fmt.Errorf("data struct parse error: %s", err.Error())
+	}
+
+	l.sets[name] = &repositoryTarget[T]{repo: repo, schema: stmt.Schema}
+	l.order = append(l.order, name)
+
+	return nil
+}
+
+// Entity returns the loaded entity identified by "set.label".
+//
+// Usage:
+// alice, err := fixtures.Entity[User](loader, "users.alice")
+func Entity[T any](l *Loader, label string) (*T, error) {
+	entity, ok := l.entities[label]
+	if !ok {
+		return nil, fmt.Errorf("unknown fixture: %s", label)
+	}
+
+	typed, ok := entity.(*T)
+	if !ok {
+		return nil, fmt.Errorf("the fixture %s is a %T", label, entity)
+	}
+
+	return typed, nil
+}
+
+// Ref returns the primary key of the loaded entity identified by "set.label".
+func (l *Loader) Ref(label string) (interface{}, bool) {
+	id, ok := l.refs[label]
+	return id, ok
+}
+
+// LoadFiles loads the fixture files in order.
+func (l *Loader) LoadFiles(paths ...string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err = l.Load(data); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Load loads the fixtures of a YAML or JSON document, in the order they are written.
+func (l *Loader) Load(data []byte) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("invalid fixtures: %v", err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	sets := document.Content[0]
+	if sets.Kind != yaml.MappingNode {
+		return errors.New("invalid fixtures: expected a map of fixture sets")
+	}
+
+	for i := 0; i < len(sets.Content); i += 2 {
+		name, labels := sets.Content[i].Value, sets.Content[i+1]
+
+		set, ok := l.sets[name]
+		if !ok {
+			return fmt.Errorf("unknown fixture set: %s", name)
+		}
+
+		if labels.Kind != yaml.MappingNode {
+			return fmt.Errorf("invalid fixture set %s: expected a map of labeled entities", name)
+		}
+
+		for j := 0; j < len(labels.Content); j += 2 {
+			label := name + "." + labels.Content[j].Value
+
+			var values map[string]interface{}
+			if err := labels.Content[j+1].Decode(&values); err != nil {
+				return fmt.Errorf("invalid fixture %s: %v", label, err)
+			}
+
+			entity, id, err := set.create(values, l.render)
+			if err != nil {
+				return fmt.Errorf("fixture %s: %v", label, err)
+			}
+
+			l.entities[label] = entity
+			l.refs[label] = id
+		}
+	}
+
+	return nil
+}
+
+// Truncate removes all the rows of the tables of the registered sets, in the reverse order of
+// registration, and forgets the loaded fixtures.
+func (l *Loader) Truncate() error {
+	for i := len(l.order) - 1; i >= 0; i-- {
+		if err := l.sets[l.order[i]].truncate(l.db); err != nil {
+			return fmt.Errorf("truncate %s: %v", l.order[i], err)
+		}
+	}
+
+	l.entities = make(map[string]interface{})
+	l.refs = make(map[string]interface{})
+
+	return nil
+}
+
+// Reload truncates the tables of the registered sets and loads the fixture files.
+func (l *Loader) Reload(paths ...string) error {
+	if err := l.Truncate(); err != nil {
+		return err
+	}
+
+	return l.LoadFiles(paths...)
+}
+
+// render executes a value as a template with the fixture functions.
+func (l *Loader) render(value string) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	funcs := template.FuncMap{
+		"uuid": uuid.NewString,
+		"now":  func() string { return time.Now().Format(time.RFC3339Nano) },
+		"ago": func(duration string) (string, error) {
+			d, err := time.ParseDuration(duration)
+			return time.Now().Add(-d).Format(time.RFC3339Nano), err
+		},
+		"fromNow": func(duration string) (string, error) {
+			d, err := time.ParseDuration(duration)
+			return time.Now().Add(d).Format(time.RFC3339Nano), err
+		},
+		"ref": func(label string) (string, error) {
+			id, ok := l.refs[label]
+			if !ok {
+				return "", fmt.Errorf("unknown fixture reference: %s", label)
+			}
+
+			return fmt.Sprint(id), nil
+		},
+	}
+
+	tmpl, err := template.New("value").Funcs(funcs).Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (t *repositoryTarget[T]) create(values map[string]interface{}, render func(string) (string, error)) (interface{}, interface{}, error) {
+	ctx := context.Background()
+	entity := new(T)
+	rv := reflect.ValueOf(entity).Elem()
+
+	for column, value := range values {
+		field := t.schema.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("unknown column: %s", column)
+		}
+
+		if text, ok := value.(string); ok {
+			rendered, err := render(text)
+			if err != nil {
+				return nil, nil, fmt.Errorf("column %s: %v", column, err)
+			}
+
+			value = rendered
+
+			// Times are rendered as RFC 3339, which GORM would parse in the local time zone.
+			if field.DataType == schema.Time {
+				if parsed, err := time.Parse(time.RFC3339Nano, rendered); err == nil {
+					value = parsed
+				}
+			}
+		}
+
+		if err := field.Set(ctx, rv, value); err != nil {
+			return nil, nil, fmt.Errorf("column %s: %v", column, err)
+		}
+	}
+
+	if err := t.repo.Create(entity); err != nil {
+		return nil, nil, err
+	}
+
+	var id interface{}
+	if field := t.schema.PrioritizedPrimaryField; field != nil {
+		id, _ = field.ValueOf(ctx, rv)
+	}
+
+	return entity, id, nil
+}
+
+func (t *repositoryTarget[T]) truncate(db *gorm.DB) error {
+	return db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(new(T)).Error
+}
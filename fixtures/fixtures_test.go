@@ -0,0 +1,131 @@
+package fixtures
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fixtureAuthor struct {
+	ID       uint   `gorm:"primaryKey"`
+	Name     string `gorm:"not null"`
+	Token    string `gorm:"unique"`
+	JoinedAt time.Time
+}
+
+type fixtureBook struct {
+	ID       uint `gorm:"primaryKey"`
+	Title    string
+	Pages    int
+	AuthorID uint
+	Author   fixtureAuthor
+}
+
+func newLoader(t *testing.T) (*Loader, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("./database.db"), &gorm.Config{})
+	assert.Nil(t, err)
+
+	db.AutoMigrate(&fixtureAuthor{}, &fixtureBook{})
+
+	authors, err := gormet.New[fixtureAuthor](db)
+	assert.Nil(t, err)
+
+	books, err := gormet.New[fixtureBook](db)
+	assert.Nil(t, err)
+
+	loader := New(db)
+	assert.Nil(t, Register(loader, "fixture_authors", authors))
+	assert.Nil(t, Register(loader, "fixture_books", books))
+
+	return loader, db
+}
+
+func TestLoader(t *testing.T) {
+	loader, db := newLoader(t)
+
+	t.Run("Reload YAML and JSON files", func(t *testing.T) {
+		err := loader.Reload("testdata/fixtures.yml", "testdata/fixtures.json")
+		assert.Nil(t, err)
+
+		var authors, books int64
+		db.Model(&fixtureAuthor{}).Count(&authors)
+		db.Model(&fixtureBook{}).Count(&books)
+		assert.Equal(t, int64(3), authors)
+		assert.Equal(t, int64(2), books)
+	})
+
+	t.Run("References and templates", func(t *testing.T) {
+		alice, err := Entity[fixtureAuthor](loader, "fixture_authors.alice")
+		assert.Nil(t, err)
+		assert.Len(t, alice.Token, 36)
+		assert.WithinDuration(t, time.Now().Add(-48*time.Hour), alice.JoinedAt, time.Minute)
+
+		carol, err := Entity[fixtureAuthor](loader, "fixture_authors.carol")
+		assert.Nil(t, err)
+		assert.True(t, carol.JoinedAt.After(time.Now()))
+
+		book, err := Entity[fixtureBook](loader, "fixture_books.go")
+		assert.Nil(t, err)
+		assert.Equal(t, alice.ID, book.AuthorID)
+		assert.Equal(t, 300, book.Pages)
+
+		id, ok := loader.Ref("fixture_books.gorm")
+		assert.True(t, ok)
+		assert.NotZero(t, id)
+	})
+
+	t.Run("Reload replaces the rows", func(t *testing.T) {
+		assert.Nil(t, loader.Reload("testdata/fixtures.yml"))
+
+		var authors int64
+		db.Model(&fixtureAuthor{}).Count(&authors)
+		assert.Equal(t, int64(2), authors)
+
+		_, err := Entity[fixtureAuthor](loader, "fixture_authors.carol")
+		assert.Equal(t, "unknown fixture: fixture_authors.carol", err.Error())
+	})
+
+	t.Run("Wrong entity type", func(t *testing.T) {
+		_, err := Entity[fixtureBook](loader, "fixture_authors.alice")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLoader_Errors(t *testing.T) {
+	loader, _ := newLoader(t)
+	assert.Nil(t, loader.Truncate())
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "Unknown set", input: "unknown:\n  a:\n    name: x", err: "unknown fixture set: unknown"},
+		{name: "Unknown column", input: "fixture_authors:\n  a:\n    age: 1", err: "fixture fixture_authors.a: unknown column: age"},
+		{name: "Unknown reference", input: "fixture_books:\n  a:\n    author_id: '{{ ref \"fixture_authors.nobody\" }}'", err: "unknown fixture reference"},
+		{name: "Not a map", input: "- a\n- b", err: "invalid fixtures: expected a map of fixture sets"},
+		{name: "Invalid set", input: "fixture_authors: [1, 2]", err: "invalid fixture set fixture_authors: expected a map of labeled entities"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loader.Load([]byte(tt.input))
+
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		assert.NotNil(t, loader.LoadFiles("testdata/missing.yml"))
+	})
+
+	t.Run("Invalid registration", func(t *testing.T) {
+		assert.NotNil(t, Register[fixtureAuthor](loader, "fixture_authors", nil))
+		assert.NotNil(t, Register[fixtureAuthor](loader, "", nil))
+	})
+}
@@ -3,6 +3,9 @@ package gormet
 import (
 	"errors"
 	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Get retrieves a single entity from the database based on the provided filter criteria.
@@ -33,17 +36,22 @@ import (
 func (r *Repository[T]) Get(entity T) (*T, error) {
 	retrievedEntity := new(T)
 
-	result := r.db.First(retrievedEntity, entity)
+	err := r.retry(func() error {
+		return r.scoped(r.db).First(retrievedEntity, entity).Error
+	})
 
-	if result.Error != nil {
-		return nil, result.Error
+	if err != nil {
+		return nil, err
 	}
 
+	r.track(retrievedEntity)
+
 	return retrievedEntity, nil
 }
 
 // GetById retrieves a single entity from the database based on its unique identifier (id).
 // It takes a pointer to the repository and the id of the entity, and returns a pointer to the retrieved entity and an error, if any.
+// When the repository is configured WithCache, the entity is served from the cache when available.
 //
 // Example:
 //
@@ -72,19 +80,46 @@ func (r *Repository[T]) GetById(id interface{}) (*T, error) {
 		return nil, errors.New("the id should not be nil")
 	}
 
+	// Inside a transaction the cache is bypassed, so uncommitted changes are never cached. An unscoped
+	// repository bypasses it too, as the cache only holds entities matching the default scopes.
+	useCache := r.config.cache != nil && !r.inTx && !r.unscoped
+
+	if useCache {
+		// The cache stores values, so the caller gets a copy it can't use to modify the cached entity.
+		if cached, ok := r.config.cache.Get(r.cacheKey(id)); ok {
+			if entity, ok := cached.(T); ok {
+				r.track(&entity)
+				return &entity, nil
+			}
+		}
+	}
+
 	retrievedEntity := new(T)
-	result := r.db.First(retrievedEntity, fmt.Sprintf("%s = ?", r.pkName), id)
 
-	if result.Error != nil {
-		return nil, result.Error
+	err := r.retry(func() error {
+		return r.scoped(r.db).First(retrievedEntity, fmt.Sprintf("%s = ?", r.pkName), id).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if useCache {
+		r.config.cache.Set(r.cacheKey(id), *retrievedEntity, r.config.cacheTTL)
 	}
 
+	r.track(retrievedEntity)
+
 	return retrievedEntity, nil
 }
 
 // GetLatest retrieves the latest entity from the database without any filter criteria.
 // It takes a pointer to the repository and returns a pointer to the retrieved entity and an error, if any.
 //
+// The recency is defined by the column set WithLatestColumn, the field tagged with `gormet:"latest"`,
+// the CreatedAt field (as in gorm.Model) or the primary key, in this order. Ties are broken by the
+// primary key, so the result is deterministic even for non-sequential keys such as UUIDs.
+//
 // Example:
 //
 //	userRepo, err := NewUserRepository(db)
@@ -102,13 +137,102 @@ func (r *Repository[T]) GetById(id interface{}) (*T, error) {
 // - A pointer to the retrieved entity.
 // - An error if the retrieval operation encounters any issues.
 func (r *Repository[T]) GetLatest() (*T, error) {
+	return r.getByRecency(true)
+}
+
+// GetOldest retrieves the oldest entity from the database without any filter criteria.
+// It uses the same recency column as GetLatest, in ascending order.
+//
+// Example:
+//
+//	// Retrieve the oldest user entity from the database
+//	oldestUser, err := userRepo.GetOldest()
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Returns:
+// - A pointer to the retrieved entity.
+// - An error if the retrieval operation encounters any issues.
+func (r *Repository[T]) GetOldest() (*T, error) {
+	return r.getByRecency(false)
+}
+
+// GetLatestN retrieves the n latest entities matching the given criteria, the latest first.
+// It uses the same recency column as GetLatest.
+//
+// Example:
+//
+//	// Retrieve the 5 latest active users
+//	users, err := userRepo.GetLatestN(5, "active = ?", true)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - n: The maximum number of entities to retrieve.
+// - query: GORM query condition, nil to consider all the entities.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - The retrieved entities, empty if none matches.
+// - An error if the retrieval operation encounters any issues.
+func (r *Repository[T]) GetLatestN(n uint, query interface{}, args ...interface{}) ([]T, error) {
+	entities := make([]T, 0)
+
+	if n == 0 {
+		return entities, nil
+	}
+
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return entities, err
+	}
+
+	err = r.retry(func() error {
+		entities = entities[:0]
+		tx := r.scoped(r.db)
+
+		if query != nil {
+			tx = tx.Where(query, args...)
+		}
+
+		return r.orderByRecency(tx, true).Limit(int(n)).Find(&entities).Error
+	})
+
+	if err != nil {
+		return []T{}, err
+	}
+
+	r.trackAll(entities)
+
+	return entities, nil
+}
+
+// getByRecency retrieves the latest or the oldest entity.
+func (r *Repository[T]) getByRecency(desc bool) (*T, error) {
 	retrievedEntity := new(T)
 
-	result := r.db.Order(fmt.Sprintf("%s DESC", r.pkName)).First(retrievedEntity)
+	err := r.retry(func() error {
+		return r.orderByRecency(r.scoped(r.db), desc).First(retrievedEntity).Error
+	})
 
-	if result.Error != nil {
-		return nil, result.Error
+	if err != nil {
+		return nil, err
 	}
 
+	r.track(retrievedEntity)
+
 	return retrievedEntity, nil
 }
+
+// orderByRecency orders the query by the recency column, then by primary key.
+func (r *Repository[T]) orderByRecency(tx *gorm.DB, desc bool) *gorm.DB {
+	tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: r.latest}, Desc: desc})
+
+	if r.latest != r.pkName {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: r.pkName}, Desc: desc})
+	}
+
+	return tx
+}
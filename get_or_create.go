@@ -0,0 +1,130 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// getOrCreateAttempts is the number of times GetOrCreate looks the entity up again after
+// losing a creation race to a concurrent caller.
+const getOrCreateAttempts = 3
+
+// GetOrCreate retrieves the entity matching the filter or creates it when none exists.
+//
+// The filter works as in Get: its non-zero fields are the lookup criteria. When no entity matches,
+// a new one is created from the defaults overlaid with the non-zero fields of the filter. If the
+// creation fails with a duplicate key error, because a concurrent caller created the same entity
+// in the meantime, the lookup is retried and the entity created by the other caller is returned.
+// The filter should therefore cover a unique constraint of the table. To fail when no entity
+// matches instead, use Get, which returns gorm.ErrRecordNotFound.
+//
+// Usage:
+//
+//	tag, created, err := repo.GetOrCreate(Tag{Name: "golang"}, Tag{Color: "blue"})
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - filter: An entity whose non-zero fields identify the entity to retrieve.
+// - defaults: An entity whose non-zero fields are used to initialize the created entity.
+//
+// Returns:
+// - A pointer to the retrieved or created entity.
+// - true if the entity was created, false if it was retrieved.
+// - An error if the retrieval or the creation encounters any issues.
+func (r *Repository[T]) GetOrCreate(filter T, defaults T) (*T, bool, error) {
+	var err error
+
+	for attempt := 0; attempt < getOrCreateAttempts; attempt++ {
+		entity, initialized, getErr := r.GetOrInit(filter, defaults)
+		if getErr != nil || !initialized {
+			return entity, false, getErr
+		}
+
+		if err = r.createIsolated(entity); err == nil {
+			return entity, true, nil
+		}
+
+		if !IsDuplicateKey(err) {
+			return nil, false, err
+		}
+	}
+
+	return nil, false, err
+}
+
+// GetOrInit retrieves the entity matching the filter or, when none exists, builds a new one
+// without persisting it.
+//
+// The new entity is made of the defaults overlaid with the non-zero fields of the filter, as in GetOrCreate.
+//
+// Usage:
+//
+//	tag, initialized, err := repo.GetOrInit(Tag{Name: "golang"}, Tag{Color: "blue"})
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - filter: An entity whose non-zero fields identify the entity to retrieve.
+// - defaults: An entity whose non-zero fields are used to initialize the new entity.
+//
+// Returns:
+// - A pointer to the retrieved or the new entity.
+// - true if the entity was built, false if it was retrieved.
+// - An error if the retrieval encounters any issues.
+func (r *Repository[T]) GetOrInit(filter T, defaults T) (*T, bool, error) {
+	entity, err := r.Get(filter)
+
+	if err == nil {
+		return entity, false, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	entity = &defaults
+	if err = r.overlay(entity, &filter); err != nil {
+		return nil, false, err
+	}
+
+	return entity, true, nil
+}
+
+// createIsolated creates the entity. Inside a transaction, the creation runs in a savepoint so
+// a duplicate key error doesn't abort the whole transaction on databases such as PostgreSQL.
+func (r *Repository[T]) createIsolated(entity *T) error {
+	if !r.inTx {
+		return r.Create(entity)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.withTx(tx).Create(entity)
+	})
+}
+
+// overlay copies the non-zero fields of src into dst.
+func (r *Repository[T]) overlay(dst *T, src *T) error {
+	ctx := context.Background()
+	dstValue := reflect.ValueOf(dst).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+
+	for _, field := range r.schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		if value, zero := field.ValueOf(ctx, srcValue); !zero {
+			if err := field.Set(ctx, dstValue, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
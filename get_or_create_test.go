@@ -0,0 +1,123 @@
+package gormet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testGetOrCreate struct {
+	gorm.Model
+	Name  string `json:"name" gorm:"unique;not null;default:null"`
+	Color string `json:"color"`
+	Size  int    `json:"size"`
+}
+
+func TestRepository_GetOrCreate(t *testing.T) {
+	db := getGormConnection(t, &testGetOrCreate{})
+
+	repo, err := New[testGetOrCreate](db)
+	assert.Nil(t, err)
+
+	t.Run("Create when missing", func(t *testing.T) {
+		name := uuid.NewString()
+
+		got, created, err := repo.GetOrCreate(testGetOrCreate{Name: name}, testGetOrCreate{Name: "ignored", Color: "blue"})
+		assert.Nil(t, err)
+		assert.True(t, created)
+		assert.NotZero(t, got.ID)
+		assert.Equal(t, name, got.Name)
+		assert.Equal(t, "blue", got.Color)
+	})
+
+	t.Run("Get when existing", func(t *testing.T) {
+		entity := &testGetOrCreate{Name: uuid.NewString(), Color: "red"}
+		assert.Nil(t, repo.Create(entity))
+
+		got, created, err := repo.GetOrCreate(testGetOrCreate{Name: entity.Name}, testGetOrCreate{Color: "blue"})
+		assert.Nil(t, err)
+		assert.False(t, created)
+		assert.Equal(t, entity.ID, got.ID)
+		assert.Equal(t, "red", got.Color)
+	})
+
+	t.Run("Lost creation race", func(t *testing.T) {
+		name := uuid.NewString()
+		var winner *testGetOrCreate
+
+		// A concurrent caller creates the entity between the lookup and the creation.
+		racing, err := New[testGetOrCreate](db, WithHooks(Hooks{
+			BeforeCreate: func(tx *gorm.DB, entity interface{}) error {
+				if winner == nil {
+					winner = &testGetOrCreate{Name: name, Color: "green"}
+					return db.Create(winner).Error
+				}
+
+				return nil
+			},
+		}))
+		assert.Nil(t, err)
+
+		got, created, err := racing.GetOrCreate(testGetOrCreate{Name: name}, testGetOrCreate{Color: "blue"})
+		assert.Nil(t, err)
+		assert.False(t, created)
+		assert.Equal(t, winner.ID, got.ID)
+		assert.Equal(t, "green", got.Color)
+	})
+
+	t.Run("Inside a transaction", func(t *testing.T) {
+		name := uuid.NewString()
+
+		err := repo.Transaction(func(tx *Repository[testGetOrCreate]) error {
+			got, created, err := tx.GetOrCreate(testGetOrCreate{Name: name}, testGetOrCreate{Size: 3})
+			assert.True(t, created)
+			assert.Equal(t, 3, got.Size)
+
+			return err
+		})
+		assert.Nil(t, err)
+
+		_, err = repo.Get(testGetOrCreate{Name: name})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Creation error", func(t *testing.T) {
+		got, created, err := repo.GetOrCreate(testGetOrCreate{Color: uuid.NewString()}, testGetOrCreate{})
+		assert.NotNil(t, err)
+		assert.False(t, created)
+		assert.Nil(t, got)
+	})
+}
+
+func TestRepository_GetOrInit(t *testing.T) {
+	db := getGormConnection(t, &testGetOrCreate{})
+
+	repo, err := New[testGetOrCreate](db)
+	assert.Nil(t, err)
+
+	t.Run("Build without persisting", func(t *testing.T) {
+		name := uuid.NewString()
+
+		got, initialized, err := repo.GetOrInit(testGetOrCreate{Name: name}, testGetOrCreate{Color: "blue"})
+		assert.Nil(t, err)
+		assert.True(t, initialized)
+		assert.Zero(t, got.ID)
+		assert.Equal(t, name, got.Name)
+		assert.Equal(t, "blue", got.Color)
+
+		_, err = repo.Get(testGetOrCreate{Name: name})
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("Get when existing", func(t *testing.T) {
+		entity := &testGetOrCreate{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		got, initialized, err := repo.GetOrInit(testGetOrCreate{Name: entity.Name}, testGetOrCreate{})
+		assert.Nil(t, err)
+		assert.False(t, initialized)
+		assert.Equal(t, entity.ID, got.ID)
+	})
+}
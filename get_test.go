@@ -3,6 +3,7 @@ package gormet
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -179,3 +180,96 @@ func TestRepository_GetLatest(t *testing.T) {
 		assert.Equal(t, err.Error(), "sql: database is closed")
 	})
 }
+
+// testLatestUUID data structure with a non-sequential primary key
+type testLatestUUID struct {
+	ID        string `gorm:"primaryKey"`
+	Name      string
+	CreatedAt time.Time
+}
+
+// testLatestTag data structure with a tagged recency field
+type testLatestTag struct {
+	ID          uint `gorm:"primaryKey"`
+	Name        string
+	PublishedAt time.Time `gormet:"latest"`
+}
+
+func TestRepository_GetLatestOrdering(t *testing.T) {
+	db := getGormConnection(t, &testLatestUUID{})
+	db.AutoMigrate(&testLatestTag{})
+
+	t.Run("Resolve the recency column", func(t *testing.T) {
+		uuidRepo, err := New[testLatestUUID](db)
+		assert.Nil(t, err)
+		assert.Equal(t, "created_at", uuidRepo.latest)
+
+		tagRepo, err := New[testLatestTag](db)
+		assert.Nil(t, err)
+		assert.Equal(t, "published_at", tagRepo.latest)
+
+		optionRepo, err := New[testLatestTag](db, WithLatestColumn("Name"))
+		assert.Nil(t, err)
+		assert.Equal(t, "name", optionRepo.latest)
+
+		_, err = New[testLatestTag](db, WithLatestColumn("unknown"))
+		assert.Equal(t, `invalid option: unknown latest column: "unknown"`, err.Error())
+	})
+
+	t.Run("UUID primary key ordered by creation time", func(t *testing.T) {
+		db.Where("1 = 1").Delete(&testLatestUUID{})
+
+		repo, err := New[testLatestUUID](db)
+		assert.Nil(t, err)
+
+		base := time.Now()
+		var created []testLatestUUID
+		for n := 0; n < 5; n++ {
+			// UUIDs are random, so the primary key order doesn't follow the creation order.
+			entity := testLatestUUID{ID: uuid.NewString(), Name: fmt.Sprint(n), CreatedAt: base.Add(time.Duration(n) * time.Minute)}
+			assert.Nil(t, repo.Create(&entity))
+
+			created = append(created, entity)
+		}
+
+		latest, err := repo.GetLatest()
+		assert.Nil(t, err)
+		assert.Equal(t, created[4].ID, latest.ID)
+
+		oldest, err := repo.GetOldest()
+		assert.Nil(t, err)
+		assert.Equal(t, created[0].ID, oldest.ID)
+
+		latestN, err := repo.GetLatestN(2, "name <> ?", "4")
+		assert.Nil(t, err)
+		assert.Len(t, latestN, 2)
+		assert.Equal(t, created[3].ID, latestN[0].ID)
+		assert.Equal(t, created[2].ID, latestN[1].ID)
+
+		none, err := repo.GetLatestN(0, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("Tagged field", func(t *testing.T) {
+		repo, err := New[testLatestTag](db)
+		assert.Nil(t, err)
+
+		recent := &testLatestTag{Name: uuid.NewString(), PublishedAt: time.Now().Add(time.Hour)}
+		assert.Nil(t, repo.Create(recent))
+		assert.Nil(t, repo.Create(&testLatestTag{Name: uuid.NewString(), PublishedAt: time.Now()}))
+
+		latest, err := repo.GetLatest()
+		assert.Nil(t, err)
+		assert.Equal(t, recent.ID, latest.ID)
+	})
+
+	t.Run("Error on invalid criteria", func(t *testing.T) {
+		repo, err := New[testLatestUUID](db)
+		assert.Nil(t, err)
+
+		entities, err := repo.GetLatestN(1, "this_field_generate_error = ?", 1)
+		assert.NotNil(t, err)
+		assert.Empty(t, entities)
+	})
+}
@@ -0,0 +1,347 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Version is a snapshot of an entity recorded in the history table, valid from ValidFrom until ValidTo.
+type Version[T any] struct {
+	Entity    T          // The entity as it was written.
+	ValidFrom time.Time  // When the version was written.
+	ValidTo   *time.Time // When the version was replaced or deleted, nil for the current version.
+}
+
+// Columns added by the history table to the columns of the model.
+const (
+	historyIdColumn        = "history_id"
+	historyValidFromColumn = "valid_from"
+	historyValidToColumn   = "valid_to"
+)
+
+// errHistoryDisabled is returned by the temporal queries of a repository created without WithHistory.
+var errHistoryDisabled = errors.New("the history is not enabled, use the WithHistory option")
+
+// temporal describes the history table of a model and records its versions.
+type temporal struct {
+	table   string          // The name of the history table.
+	source  string          // The name of the table of the model.
+	pkName  string          // The primary key of the model, identifying the versions of an entity.
+	columns []string        // The columns of the model copied into the history table.
+	fields  []*schema.Field // The fields of the model, in the order of the columns.
+	model   reflect.Type    // The struct mapped to the history table.
+}
+
+// newTemporal describes the history table of the model, named after its table with the _history suffix.
+func newTemporal(sch *schema.Schema, pkName string) (*temporal, error) {
+	t := &temporal{
+		table:  sch.Table + "_history",
+		source: sch.Table,
+		pkName: pkName,
+	}
+
+	index := "idx_" + t.table + "_version"
+
+	structFields := []reflect.StructField{{
+		Name: "HistoryID",
+		Type: reflect.TypeOf(uint(0)),
+		Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s;primaryKey;autoIncrement"`, historyIdColumn)),
+	}}
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+
+		switch field.DBName {
+		case historyIdColumn, historyValidFromColumn, historyValidToColumn:
+			return nil, fmt.Errorf("the column %q is reserved by the history table", field.DBName)
+		}
+
+		// Only the column and its storage are kept: the history holds many versions of each
+		// entity, so the keys, unique constraints and defaults of the model don't apply.
+		settings := []string{"column:" + field.DBName}
+		for _, name := range []string{"TYPE", "SIZE", "SERIALIZER"} {
+			if value, ok := field.TagSettings[name]; ok {
+				settings = append(settings, strings.ToLower(name)+":"+value)
+			}
+		}
+
+		if field.DBName == pkName {
+			settings = append(settings, "index:"+index+",priority:1")
+		}
+
+		t.columns = append(t.columns, field.DBName)
+		t.fields = append(t.fields, field)
+
+		structFields = append(structFields, reflect.StructField{
+			Name: fmt.Sprintf("Field%d", len(t.fields)),
+			Type: field.FieldType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"%s"`, strings.Join(settings, ";"))),
+		})
+	}
+
+	structFields = append(structFields,
+		reflect.StructField{
+			Name: "ValidFrom",
+			Type: reflect.TypeOf(time.Time{}),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s;not null;index:%s,priority:2"`, historyValidFromColumn, index)),
+		},
+		reflect.StructField{
+			Name: "ValidTo",
+			Type: reflect.TypeOf((*time.Time)(nil)),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s"`, historyValidToColumn)),
+		},
+	)
+
+	t.model = reflect.StructOf(structFields)
+
+	return t, nil
+}
+
+// hooks returns the hooks recording a version for each write operation.
+func (t *temporal) hooks(pk *schema.Field) Hooks {
+	id := func(entity interface{}) interface{} {
+		value, _ := pk.ValueOf(context.Background(), reflect.ValueOf(entity).Elem())
+		return value
+	}
+
+	return Hooks{
+		AfterCreate: func(tx *gorm.DB, entity interface{}) error {
+			return t.open(tx, id(entity), time.Now())
+		},
+		AfterUpdate: func(tx *gorm.DB, entity interface{}) error {
+			now := time.Now()
+
+			if err := t.close(tx, id(entity), now); err != nil {
+				return err
+			}
+
+			return t.open(tx, id(entity), now)
+		},
+		AfterDelete: func(tx *gorm.DB, entity interface{}) error {
+			return t.close(tx, id(entity), time.Now())
+		},
+	}
+}
+
+// open copies the stored entity into a new version of the history, valid from the given time.
+func (t *temporal) open(tx *gorm.DB, id interface{}, from time.Time) error {
+	columns := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		columns[i] = tx.Statement.Quote(column)
+	}
+
+	list := strings.Join(columns, ", ")
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s, %s) SELECT %s, ? FROM %s WHERE %s = ?",
+		tx.Statement.Quote(t.table), list, tx.Statement.Quote(historyValidFromColumn),
+		list, tx.Statement.Quote(t.source), tx.Statement.Quote(t.pkName))
+
+	return tx.Exec(sql, from, id).Error
+}
+
+// close ends the current version of the entity at the given time.
+func (t *temporal) close(tx *gorm.DB, id interface{}, to time.Time) error {
+	sql := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ? AND %s IS NULL",
+		tx.Statement.Quote(t.table), tx.Statement.Quote(historyValidToColumn),
+		tx.Statement.Quote(t.pkName), tx.Statement.Quote(historyValidToColumn))
+
+	return tx.Exec(sql, to, id).Error
+}
+
+// findVersions loads the versions selected by the statement from the history table.
+func findVersions[T any](t *temporal, tx *gorm.DB) ([]Version[T], error) {
+	rows := reflect.New(reflect.SliceOf(t.model))
+
+	// The history keeps the deleted_at column of soft deleted models as a plain value.
+	if err := tx.Unscoped().Table(t.table).Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version[T], rows.Elem().Len())
+
+	for i := range versions {
+		row := rows.Elem().Index(i)
+		target := reflect.ValueOf(&versions[i].Entity).Elem()
+
+		for j, field := range t.fields {
+			field.ReflectValueOf(context.Background(), target).Set(row.Field(j + 1))
+		}
+
+		versions[i].ValidFrom = row.FieldByName("ValidFrom").Interface().(time.Time)
+		versions[i].ValidTo = row.FieldByName("ValidTo").Interface().(*time.Time)
+	}
+
+	return versions, nil
+}
+
+// MigrateHistory creates or updates the history table of a repository created WithHistory,
+// named after the table of the model with the _history suffix.
+//
+// Usage:
+//
+//	if err := repo.MigrateHistory(); err != nil {
+//		// Handle error
+//	}
+//
+// Returns:
+// - An error if the history is not enabled or if the table can't be migrated.
+func (r *Repository[T]) MigrateHistory() error {
+	if r.config.temporal == nil {
+		return errHistoryDisabled
+	}
+
+	return r.db.Table(r.config.temporal.table).AutoMigrate(reflect.New(r.config.temporal.model).Interface())
+}
+
+// GetByIdAsOf retrieves the entity with the given id as it was at the given time, from the history table.
+//
+// Usage:
+//
+//	// Retrieve the user as it was a week ago
+//	user, err := userRepo.GetByIdAsOf(userId, time.Now().AddDate(0, 0, -7))
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity.
+// - at: The point in time of the snapshot.
+//
+// Returns:
+// - A pointer to the snapshot of the entity.
+// - gorm.ErrRecordNotFound if the entity did not exist at that time, or another error if the history can't be read.
+func (r *Repository[T]) GetByIdAsOf(id interface{}, at time.Time) (*T, error) {
+	if id == nil {
+		return nil, errors.New("the id should not be nil")
+	}
+
+	entities, err := r.searchAsOf(at, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(fmt.Sprintf("%s = ?", r.pkName), id)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entities) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &entities[0], nil
+}
+
+// History retrieves all the versions of the entity with the given id, the oldest first.
+// After a delete, the last version has a ValidTo time and no current version follows.
+//
+// Usage:
+//
+//	versions, err := userRepo.History(userId)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity.
+//
+// Returns:
+// - The versions of the entity, empty if it has no history.
+// - An error if the history is not enabled or can't be read.
+func (r *Repository[T]) History(id interface{}) ([]Version[T], error) {
+	if id == nil {
+		return []Version[T]{}, errors.New("the id should not be nil")
+	}
+
+	if r.config.temporal == nil {
+		return []Version[T]{}, errHistoryDisabled
+	}
+
+	var versions []Version[T]
+
+	err := r.retry(func() error {
+		var err error
+		versions, err = findVersions[T](r.config.temporal, r.db.
+			Where(fmt.Sprintf("%s = ?", r.pkName), id).
+			Order(historyValidFromColumn).Order(historyIdColumn))
+
+		return err
+	})
+
+	if err != nil {
+		return []Version[T]{}, err
+	}
+
+	return versions, nil
+}
+
+// SearchAsOf retrieves the snapshots of the entities matching the criteria at the given time,
+// ordered by primary key. The criteria is evaluated against the snapshots, not the current entities.
+//
+// Usage:
+//
+//	// Retrieve the users that were active at the end of last year
+//	users, err := userRepo.SearchAsOf(endOfYear, "active = ?", true)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - at: The point in time of the snapshots.
+// - query: GORM query condition or Specification, nil to consider all the entities.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - The snapshots of the matching entities, empty if none matches.
+// - An error if the history is not enabled or can't be read.
+func (r *Repository[T]) SearchAsOf(at time.Time, query interface{}, args ...interface{}) ([]T, error) {
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return []T{}, err
+	}
+
+	return r.searchAsOf(at, func(tx *gorm.DB) *gorm.DB {
+		if query != nil {
+			tx = tx.Where(query, args...)
+		}
+
+		return tx.Order(r.pkName)
+	})
+}
+
+// searchAsOf retrieves the versions valid at the given time and matching the scope.
+func (r *Repository[T]) searchAsOf(at time.Time, scope func(tx *gorm.DB) *gorm.DB) ([]T, error) {
+	if r.config.temporal == nil {
+		return []T{}, errHistoryDisabled
+	}
+
+	var versions []Version[T]
+
+	err := r.retry(func() error {
+		tx := r.db.Where(fmt.Sprintf("%s <= ? AND (%s IS NULL OR %s > ?)",
+			historyValidFromColumn, historyValidToColumn, historyValidToColumn), at, at)
+
+		var err error
+		versions, err = findVersions[T](r.config.temporal, scope(tx))
+
+		return err
+	})
+
+	if err != nil {
+		return []T{}, err
+	}
+
+	entities := make([]T, len(versions))
+	for i, version := range versions {
+		entities[i] = version.Entity
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,175 @@
+package gormet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testHistory struct {
+	gorm.Model
+	Name  string            `json:"name" gorm:"unique"`
+	Price int               `json:"price"`
+	Tags  map[string]string `json:"tags" gorm:"serializer:json;type:text"`
+}
+
+func getHistoryRepository(t *testing.T) *Repository[testHistory] {
+	db := getGormConnection(t, &testHistory{})
+
+	repo, err := New[testHistory](db, WithHistory())
+	assert.Nil(t, err)
+	assert.Nil(t, repo.MigrateHistory())
+
+	return repo
+}
+
+// pause makes the following writes happen strictly after the returned time.
+func pause() time.Time {
+	time.Sleep(5 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	return at
+}
+
+func TestHistory(t *testing.T) {
+	repo := getHistoryRepository(t)
+
+	t.Run("Versions of an entity", func(t *testing.T) {
+		beforeCreate := pause()
+
+		entity := &testHistory{Name: uuid.NewString(), Price: 10, Tags: map[string]string{"color": "red"}}
+		assert.Nil(t, repo.Create(entity))
+
+		afterCreate := pause()
+
+		entity.Price = 20
+		entity.Tags = map[string]string{"color": "blue"}
+		assert.Nil(t, repo.Update(entity))
+
+		afterUpdate := pause()
+
+		assert.Nil(t, repo.Delete(entity))
+
+		afterDelete := pause()
+
+		versions, err := repo.History(entity.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 2)
+		assert.Equal(t, 10, versions[0].Entity.Price)
+		assert.Equal(t, "red", versions[0].Entity.Tags["color"])
+		assert.Equal(t, entity.ID, versions[0].Entity.ID)
+		assert.Equal(t, 20, versions[1].Entity.Price)
+		assert.True(t, versions[0].ValidTo.Equal(versions[1].ValidFrom))
+		assert.NotNil(t, versions[1].ValidTo)
+
+		_, err = repo.GetByIdAsOf(entity.ID, beforeCreate)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		snapshot, err := repo.GetByIdAsOf(entity.ID, afterCreate)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, snapshot.Price)
+		assert.Equal(t, entity.Name, snapshot.Name)
+
+		snapshot, err = repo.GetByIdAsOf(entity.ID, afterUpdate)
+		assert.Nil(t, err)
+		assert.Equal(t, 20, snapshot.Price)
+		assert.Equal(t, "blue", snapshot.Tags["color"])
+
+		_, err = repo.GetByIdAsOf(entity.ID, afterDelete)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("Delete by id", func(t *testing.T) {
+		entity := &testHistory{Name: uuid.NewString(), Price: 1}
+		assert.Nil(t, repo.Create(entity))
+		assert.Nil(t, repo.DeleteById(entity.ID))
+
+		versions, err := repo.History(entity.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 1)
+		assert.NotNil(t, versions[0].ValidTo)
+	})
+
+	t.Run("Search as of", func(t *testing.T) {
+		group := uuid.NewString()
+
+		first := &testHistory{Name: group + "-1", Price: 5}
+		second := &testHistory{Name: group + "-2", Price: 50}
+		assert.Nil(t, repo.Create(first))
+		assert.Nil(t, repo.Create(second))
+
+		before := pause()
+
+		first.Price = 500
+		assert.Nil(t, repo.Update(first))
+
+		entities, err := repo.SearchAsOf(before, "name LIKE ? AND price > ?", group+"%", 10)
+		assert.Nil(t, err)
+		assert.Len(t, entities, 1)
+		assert.Equal(t, second.ID, entities[0].ID)
+
+		entities, err = repo.SearchAsOf(time.Now(), And(Like[testHistory]("name", group+"%"), Ne[testHistory]("price", 0)))
+		assert.Nil(t, err)
+		assert.Len(t, entities, 2)
+		assert.Equal(t, 500, entities[0].Price)
+
+		_, err = repo.SearchAsOf(time.Now(), Eq[testHistory]("unknown", 1))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed write is not recorded", func(t *testing.T) {
+		entity := &testHistory{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+
+		duplicate := &testHistory{Name: entity.Name}
+		assert.NotNil(t, repo.Create(duplicate))
+
+		versions, err := repo.History(entity.ID)
+		assert.Nil(t, err)
+		assert.Len(t, versions, 1)
+	})
+
+	t.Run("Unknown entity", func(t *testing.T) {
+		versions, err := repo.History(uint(1 << 30))
+		assert.Nil(t, err)
+		assert.Empty(t, versions)
+
+		_, err = repo.History(nil)
+		assert.NotNil(t, err)
+
+		_, err = repo.GetByIdAsOf(nil, time.Now())
+		assert.NotNil(t, err)
+	})
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	db := getGormConnection(t, &testHistory{})
+
+	repo, err := New[testHistory](db)
+	assert.Nil(t, err)
+
+	assert.Equal(t, errHistoryDisabled, repo.MigrateHistory())
+
+	_, err = repo.History(1)
+	assert.Equal(t, errHistoryDisabled, err)
+
+	_, err = repo.GetByIdAsOf(1, time.Now())
+	assert.Equal(t, errHistoryDisabled, err)
+
+	_, err = repo.SearchAsOf(time.Now(), nil)
+	assert.Equal(t, errHistoryDisabled, err)
+
+	t.Run("Reserved column", func(t *testing.T) {
+		type Reserved struct {
+			ID        uint
+			ValidFrom time.Time
+		}
+
+		_, err := New[Reserved](db, WithHistory())
+		assert.Equal(t, `invalid option: the column "valid_from" is reserved by the history table`, err.Error())
+	})
+}
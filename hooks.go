@@ -0,0 +1,111 @@
+package gormet
+
+import (
+	"gorm.io/gorm"
+)
+
+// HookFunc is a function invoked by the repository around a write operation.
+//
+// The tx argument is the transaction in which the write operation runs, so any statement
+// issued through it is committed or rolled back together with the operation. The entity
+// argument is a pointer to the affected entity; for DeleteById only its primary key is set.
+// Returning an error aborts the operation and rolls back the transaction.
+type HookFunc func(tx *gorm.DB, entity interface{}) error
+
+// Hooks groups the functions invoked around the write operations of a repository.
+// Any of the functions may be nil.
+type Hooks struct {
+	BeforeCreate HookFunc // Invoked before the entity is inserted.
+	AfterCreate  HookFunc // Invoked after the entity is inserted.
+	BeforeUpdate HookFunc // Invoked before the entity is updated.
+	AfterUpdate  HookFunc // Invoked after the entity is updated.
+	BeforeDelete HookFunc // Invoked before the entity is deleted.
+	AfterDelete  HookFunc // Invoked after the entity is deleted.
+}
+
+// operation identifies the write operation performed by the repository.
+type operation int
+
+const (
+	opCreate operation = iota
+	opUpdate
+	opDelete
+)
+
+// empty reports whether no function is defined.
+func (h Hooks) empty() bool {
+	return h.BeforeCreate == nil && h.AfterCreate == nil &&
+		h.BeforeUpdate == nil && h.AfterUpdate == nil &&
+		h.BeforeDelete == nil && h.AfterDelete == nil
+}
+
+// before returns the function invoked before the given operation.
+func (h Hooks) before(op operation) HookFunc {
+	switch op {
+	case opCreate:
+		return h.BeforeCreate
+	case opUpdate:
+		return h.BeforeUpdate
+	default:
+		return h.BeforeDelete
+	}
+}
+
+// after returns the function invoked after the given operation.
+func (h Hooks) after(op operation) HookFunc {
+	switch op {
+	case opCreate:
+		return h.AfterCreate
+	case opUpdate:
+		return h.AfterUpdate
+	default:
+		return h.AfterDelete
+	}
+}
+
+// write executes a write operation on a single entity. See writeAll.
+func (r *Repository[T]) write(op operation, entity interface{}, exec func(tx *gorm.DB) error) error {
+	return r.writeAll(op, []interface{}{entity}, exec)
+}
+
+// writeAll executes a write operation, wrapping it in a transaction together with the hooks
+// registered for each of the entities. Without hooks and without retry policy the operation runs
+// directly on the repository connection. A write is only retried as a whole transaction, so a
+// statement that failed after reaching the database is never replayed on its own.
+func (r *Repository[T]) writeAll(op operation, entities []interface{}, exec func(tx *gorm.DB) error) error {
+	if len(r.config.hooks) == 0 {
+		if r.config.retry == nil || r.inTx {
+			return exec(r.db)
+		}
+
+		return r.transaction(exec)
+	}
+
+	return r.transaction(func(tx *gorm.DB) error {
+		for _, hooks := range r.config.hooks {
+			if fn := hooks.before(op); fn != nil {
+				for _, entity := range entities {
+					if err := fn(tx, entity); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if err := exec(tx); err != nil {
+			return err
+		}
+
+		for _, hooks := range r.config.hooks {
+			if fn := hooks.after(op); fn != nil {
+				for _, entity := range entities {
+					if err := fn(tx, entity); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package gormet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testHooks struct {
+	gorm.Model
+	Name string `json:"name" gorm:"unique;not null;default:null"`
+}
+
+func TestRepository_Hooks(t *testing.T) {
+	db := getGormConnection(t, &testHooks{})
+
+	t.Run("Hooks called in order", func(t *testing.T) {
+		var calls []string
+		record := func(name string) HookFunc {
+			return func(tx *gorm.DB, entity interface{}) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+
+		repo, err := New[testHooks](db, WithHooks(Hooks{
+			BeforeCreate: record("before create"),
+			AfterCreate:  record("after create"),
+			BeforeUpdate: record("before update"),
+			AfterUpdate:  record("after update"),
+			BeforeDelete: record("before delete"),
+			AfterDelete:  record("after delete"),
+		}))
+		assert.Nil(t, err)
+
+		entity := &testHooks{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+		assert.Nil(t, repo.Update(entity))
+		assert.Nil(t, repo.Delete(entity))
+
+		assert.Equal(t, []string{
+			"before create", "after create",
+			"before update", "after update",
+			"before delete", "after delete",
+		}, calls)
+	})
+
+	t.Run("DeleteById sets the primary key", func(t *testing.T) {
+		var deleted uint
+		repo, err := New[testHooks](db, WithHooks(Hooks{
+			AfterDelete: func(tx *gorm.DB, entity interface{}) error {
+				deleted = entity.(*testHooks).ID
+				return nil
+			},
+		}))
+		assert.Nil(t, err)
+
+		entity := &testHooks{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+		assert.Nil(t, repo.DeleteById(entity.ID))
+		assert.Equal(t, entity.ID, deleted)
+	})
+
+	t.Run("Hook error rolls back", func(t *testing.T) {
+		repo, err := New[testHooks](db, WithHooks(Hooks{
+			AfterCreate: func(tx *gorm.DB, entity interface{}) error {
+				return errors.New("hook failed")
+			},
+		}))
+		assert.Nil(t, err)
+
+		entity := &testHooks{Name: uuid.NewString()}
+		err = repo.Create(entity)
+		assert.NotNil(t, err)
+		assert.Equal(t, "hook failed", err.Error())
+
+		var count int64
+		db.Model(&testHooks{}).Where("name = ?", entity.Name).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,292 @@
+package gormet
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// defaultImportBatchSize is the number of entities inserted at once when ImportOptions.BatchSize is not set.
+const defaultImportBatchSize = 100
+
+// ImportOptions configures Import.
+type ImportOptions[T any] struct {
+	BatchSize int                   // Number of entities inserted at once, defaults to 100.
+	Upsert    bool                  // Update the existing entities with the same primary key instead of failing.
+	Validate  func(entity *T) error // Optional validation applied to each decoded entity before it is inserted.
+}
+
+// ImportReport summarizes the result of an Import.
+type ImportReport struct {
+	Total    int        // Number of rows read from the input.
+	Imported int        // Number of rows inserted or upserted.
+	Errors   []RowError // Rows that could not be decoded, validated or inserted.
+}
+
+// RowError is the error of a single row of an Import. Rows are numbered from 1, excluding the CSV header.
+type RowError struct {
+	Row int
+	Err error
+}
+
+// Error returns the message of the error, prefixed with the row number.
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Import reads entities from the reader in the given format, validates them and inserts them in batches.
+//
+// Rows that can't be decoded, validated or inserted are reported in the ImportReport and don't prevent
+// the other rows from being imported. When a batch fails, its entities are inserted one by one so the
+// failing rows can be identified. JSON and NDJSON are decoded with the json tags of the model, while
+// CSV columns are matched to the database column names of the GORM schema.
+//
+// Usage:
+// report, err := repo.Import(file, gormet.FormatNDJSON, gormet.ImportOptions[User]{Upsert: true})
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+//	for _, rowErr := range report.Errors {
+//	    // Handle row error
+//	}
+//
+// Parameters:
+// - reader: The reader providing the encoded entities.
+// - format: The encoding of the entities.
+// - opts: The batch size, upsert mode and validation function.
+//
+// Returns:
+// - The report with the number of rows read and imported and the errors of each failing row.
+// - An error if the format is unknown or if the input can't be read, such as malformed JSON or a CSV without header.
+func (r *Repository[T]) Import(reader io.Reader, format Format, opts ImportOptions[T]) (ImportReport, error) {
+	var report ImportReport
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	var batch []*T
+	var rows []int
+
+	flush := func() {
+		if len(batch) > 0 {
+			r.importBatch(batch, rows, opts.Upsert, &report)
+			batch, rows = nil, nil
+		}
+	}
+
+	err := r.decodeEntities(reader, format, func(row int, entity *T, err error) {
+		report.Total++
+
+		if err == nil && opts.Validate != nil {
+			err = opts.Validate(entity)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: row, Err: err})
+			return
+		}
+
+		batch = append(batch, entity)
+		rows = append(rows, row)
+
+		if len(batch) >= batchSize {
+			flush()
+		}
+	})
+
+	flush()
+
+	return report, err
+}
+
+// importBatch inserts a batch of entities, falling back to one insert per entity if the batch fails.
+func (r *Repository[T]) importBatch(batch []*T, rows []int, upsert bool, report *ImportReport) {
+	insert := func(entities []*T) error {
+		values := make([]interface{}, len(entities))
+		for i, entity := range entities {
+			values[i] = entity
+		}
+
+		return r.writeAll(opCreate, values, func(tx *gorm.DB) error {
+			if upsert {
+				tx = tx.Clauses(clause.OnConflict{UpdateAll: true})
+			}
+
+			return tx.Create(entities).Error
+		})
+	}
+
+	if err := insert(batch); err == nil {
+		report.Imported += len(batch)
+		return
+	}
+
+	for i, entity := range batch {
+		if err := insert([]*T{entity}); err != nil {
+			report.Errors = append(report.Errors, RowError{Row: rows[i], Err: err})
+			continue
+		}
+
+		report.Imported++
+	}
+}
+
+// decodeEntities reads the entities from the reader, calling fn with each row number and decoded entity or decoding error.
+func (r *Repository[T]) decodeEntities(reader io.Reader, format Format, fn func(row int, entity *T, err error)) error {
+	switch format {
+	case FormatJSON:
+		return decodeJSON(reader, fn)
+	case FormatNDJSON:
+		return decodeNDJSON(reader, fn)
+	case FormatCSV:
+		return decodeCSV(r.schema, reader, fn)
+	default:
+		return fmt.Errorf("unknown format: %q", format)
+	}
+}
+
+// decodeJSON reads a JSON array of entities.
+func decodeJSON[T any](reader io.Reader, fn func(row int, entity *T, err error)) error {
+	decoder := json.NewDecoder(reader)
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return errors.New("invalid json: expected an array of entities")
+	}
+
+	for row := 1; decoder.More(); row++ {
+		entity := new(T)
+		err := decoder.Decode(entity)
+
+		var typeErr *json.UnmarshalTypeError
+		if err != nil && !errors.As(err, &typeErr) {
+			return fmt.Errorf("invalid json at row %d: %v", row, err)
+		}
+
+		fn(row, entity, err)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+
+	return nil
+}
+
+// decodeNDJSON reads one JSON entity per line, ignoring blank lines.
+func decodeNDJSON[T any](reader io.Reader, fn func(row int, entity *T, err error)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	row := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		row++
+		entity := new(T)
+		fn(row, entity, json.Unmarshal([]byte(line), entity))
+	}
+
+	return scanner.Err()
+}
+
+// decodeCSV reads a header with the column names followed by one entity per row. Unknown columns are ignored.
+func decodeCSV[T any](sch *schema.Schema, reader io.Reader, fn func(row int, entity *T, err error)) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return fmt.Errorf("invalid csv header: %v", err)
+	}
+
+	fields := make([]*schema.Field, len(header))
+	for i, column := range header {
+		if field := sch.LookUpField(strings.TrimSpace(column)); field != nil && field.DBName != "" {
+			fields[i] = field
+		}
+	}
+
+	for row := 1; ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		entity := new(T)
+
+		if err == nil {
+			err = setCSVRecord(fields, record, reflect.ValueOf(entity).Elem())
+		} else if !errors.Is(err, csv.ErrFieldCount) && !errors.Is(err, csv.ErrQuote) && !errors.Is(err, csv.ErrBareQuote) {
+			return err
+		}
+
+		fn(row, entity, err)
+	}
+}
+
+// parseCSVValue converts a CSV value into a value that can be assigned to the field.
+// Empty strings of non-string fields are reported as NULL.
+func parseCSVValue(field *schema.Field, value string) (interface{}, bool, error) {
+	if value == "" && field.DataType != schema.String {
+		return nil, false, nil
+	}
+
+	switch field.DataType {
+	case schema.Time:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return t, true, err
+	case schema.Bool:
+		b, err := strconv.ParseBool(value)
+		return b, true, err
+	}
+
+	return value, true, nil
+}
+
+// setCSVRecord assigns the values of a CSV record to the fields of the entity.
+func setCSVRecord(fields []*schema.Field, record []string, entity reflect.Value) error {
+	for i, field := range fields {
+		if field == nil || i >= len(record) {
+			continue
+		}
+
+		value, ok, err := parseCSVValue(field, record[i])
+		if err != nil {
+			return fmt.Errorf("column %s: %v", field.DBName, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err = field.Set(context.Background(), entity, value); err != nil {
+			return fmt.Errorf("column %s: %v", field.DBName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package gormet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Import(t *testing.T) {
+	db := getGormConnection(t, &testExport{})
+
+	repo, err := New[testExport](db)
+	assert.Nil(t, err)
+
+	countGroup := func(group string) int64 {
+		var count int64
+		db.Model(&testExport{}).Where("`group` = ?", group).Count(&count)
+		return count
+	}
+
+	t.Run("Round trip in every format", func(t *testing.T) {
+		for _, format := range []Format{FormatJSON, FormatNDJSON, FormatCSV} {
+			group := uuid.NewString()
+			createExport(t, repo, group, 5)
+
+			var buf bytes.Buffer
+			assert.Nil(t, repo.Export(&buf, format, "`group` = ?", group))
+			assert.Nil(t, db.Unscoped().Where("`group` = ?", group).Delete(&testExport{}).Error)
+
+			report, err := repo.Import(&buf, format, ImportOptions[testExport]{BatchSize: 2})
+			assert.Nil(t, err, format)
+			assert.Equal(t, ImportReport{Total: 5, Imported: 5}, report, format)
+			assert.Equal(t, int64(5), countGroup(group), format)
+		}
+	})
+
+	t.Run("Per row errors", func(t *testing.T) {
+		group := uuid.NewString()
+		duplicated := uuid.NewString()
+
+		input := strings.Join([]string{
+			fmt.Sprintf(`{"name": %q, "group": %q}`, uuid.NewString(), group),
+			`{"name": 42}`,
+			fmt.Sprintf(`{"name": %q, "group": %q}`, duplicated, group),
+			fmt.Sprintf(`{"name": %q, "group": %q}`, duplicated, group),
+			fmt.Sprintf(`{"name": "invalid", "group": %q}`, group),
+		}, "\n")
+
+		report, err := repo.Import(strings.NewReader(input), FormatNDJSON, ImportOptions[testExport]{
+			Validate: func(entity *testExport) error {
+				if entity.Name == "invalid" {
+					return errors.New("invalid name")
+				}
+
+				return nil
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, report.Total)
+		assert.Equal(t, 2, report.Imported)
+		assert.Len(t, report.Errors, 3)
+		assert.Equal(t, []int{2, 5, 4}, []int{report.Errors[0].Row, report.Errors[1].Row, report.Errors[2].Row})
+		assert.Equal(t, "row 5: invalid name", report.Errors[1].Error())
+		assert.Equal(t, int64(2), countGroup(group))
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		group := uuid.NewString()
+		entity := createExport(t, repo, group, 1)[0]
+
+		input := fmt.Sprintf(`[{"ID": %d, "name": %q, "group": %q}]`, entity.ID, entity.Name, "upserted-"+group)
+
+		report, err := repo.Import(strings.NewReader(input), FormatJSON, ImportOptions[testExport]{Upsert: true})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, report.Imported)
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "upserted-"+group, got.Group)
+	})
+
+	t.Run("CSV with unknown columns and invalid values", func(t *testing.T) {
+		group := uuid.NewString()
+		input := fmt.Sprintf("name,group,active,unknown\n%s,%s,true,x\n%s,%s,maybe,x\n", uuid.NewString(), group, uuid.NewString(), group)
+
+		report, err := repo.Import(strings.NewReader(input), FormatCSV, ImportOptions[testExport]{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 1, report.Imported)
+		assert.Equal(t, 2, report.Errors[0].Row)
+	})
+
+	t.Run("Malformed input", func(t *testing.T) {
+		_, err := repo.Import(strings.NewReader(`{"name": "x"}`), FormatJSON, ImportOptions[testExport]{})
+		assert.Equal(t, "invalid json: expected an array of entities", err.Error())
+
+		_, err = repo.Import(strings.NewReader(""), FormatCSV, ImportOptions[testExport]{})
+		assert.NotNil(t, err)
+
+		_, err = repo.Import(strings.NewReader(""), Format("xml"), ImportOptions[testExport]{})
+		assert.Equal(t, `unknown format: "xml"`, err.Error())
+	})
+}
@@ -0,0 +1,361 @@
+// Package lease implements distributed locks, or leases, stored in a table of the database through
+// the repositories of gormet, so replicas can elect a leader without any other infrastructure.
+//
+// A lease is held by a single owner until it is released or until its time to live expires, after
+// which any other owner can take it over. Each new holder receives a fencing token greater than the
+// one of the previous holder: resources guarded by the lease should reject writes carrying a token
+// lower than the last one they have seen, so a holder paused past its expiry can't overwrite the work
+// of its successor. Released leases are kept in the table, so their tokens keep increasing.
+//
+// Expiry is computed from the clock of each replica, so the clocks should be synchronized with a skew
+// well below the time to live.
+//
+// Usage:
+//
+//	if err := lease.AutoMigrate(db); err != nil {
+//		// Handle error
+//	}
+//
+//	leases, err := lease.New(db)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+//	// Run the cron job on a single replica
+//	err = leases.Run(ctx, "nightly-report", time.Minute, hostname, sendReport)
+//	if errors.Is(err, lease.ErrHeld) {
+//		// Another replica is running it
+//	}
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"gorm.io/gorm"
+)
+
+// ErrHeld is returned by Acquire when the lease is held by another owner and has not expired.
+var ErrHeld = errors.New("the lease is held by another owner")
+
+// ErrLost is returned when a lease can't be renewed or released because it expired, and may have
+// been taken over by another owner since.
+var ErrLost = errors.New("the lease was lost")
+
+// MinTTL is the shortest time to live accepted for a lease. Run renews a lease every third of its time
+// to live, so a shorter one would have the renewals alone keep the database busy, while the clock skew
+// between the replicas and the latency of the database make up a growing share of it.
+const MinTTL = 10 * time.Millisecond
+
+// Lease is a record of the leases table.
+type Lease struct {
+	Name       string    `json:"name" gorm:"primaryKey"`
+	Owner      string    `json:"owner" gorm:"not null"`
+	Token      int64     `json:"token" gorm:"not null"`     // Fencing token, incremented for each new holder.
+	ExpiresAt  time.Time `json:"expiresAt" gorm:"not null"` // After this time, the lease can be taken over.
+	AcquiredAt time.Time `json:"acquiredAt" gorm:"not null"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName returns the name of the leases table.
+func (Lease) TableName() string {
+	return "leases"
+}
+
+// Expired reports whether the lease has expired, and can be taken over.
+func (l *Lease) Expired() bool {
+	return !time.Now().Before(l.ExpiresAt)
+}
+
+// AutoMigrate creates or updates the leases table.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Lease{})
+}
+
+// Manager acquires, renews and releases the leases stored in the leases table.
+type Manager struct {
+	repo *gormet.Repository[Lease]
+}
+
+// New creates the manager of the leases stored in the database.
+//
+// Usage:
+// leases, err := lease.New(db, gormet.WithRetry(gormet.DefaultRetryPolicy()))
+//
+// Parameters:
+// - db: The database holding the leases table, created by AutoMigrate.
+// - opts: Options of the repository storing the leases.
+//
+// Returns:
+// - The manager.
+// - An error if the repository can't be created.
+func New(db *gorm.DB, opts ...gormet.Option) (*Manager, error) {
+	repo, err := gormet.New[Lease](db, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{repo: repo}, nil
+}
+
+// Acquire acquires the lease with the given name for the owner, during ttl.
+//
+// A lease that does not exist, was released or has expired is acquired with a new fencing token.
+// A lease already held by the same owner is extended and keeps its token, so acquiring is idempotent.
+//
+// Usage:
+// l, err := leases.Acquire("nightly-report", time.Minute, hostname)
+//
+// Parameters:
+// - name: The name of the lease.
+// - ttl: The time to live of the lease, after which it can be taken over. It should be at least MinTTL.
+// - owner: Identifies the holder, such as the host name of the replica.
+//
+// Returns:
+// - The acquired lease.
+// - ErrHeld if the lease is held by another owner, or another error if the table can't be accessed.
+func (m *Manager) Acquire(name string, ttl time.Duration, owner string) (*Lease, error) {
+	if err := validate(name, ttl, owner); err != nil {
+		return nil, err
+	}
+
+	var acquired *Lease
+
+	// The lease is read back in the transaction writing it, so it can't change hands in between.
+	err := m.repo.Transaction(func(tx *gormet.Repository[Lease]) error {
+		now := time.Now()
+
+		// Take over the lease when it is expired or released.
+		affected, err := tx.UpdateWhere("name = ? AND expires_at <= ?", map[string]interface{}{
+			"owner":       owner,
+			"token":       gorm.Expr("token + 1"),
+			"expires_at":  now.Add(ttl),
+			"acquired_at": now,
+		}, name, now)
+
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			// The insert runs in a nested transaction, so a duplicate key doesn't abort the enclosing one.
+			err = tx.Transaction(func(tx *gormet.Repository[Lease]) error {
+				return tx.Create(&Lease{Name: name, Owner: owner, Token: 1, ExpiresAt: now.Add(ttl), AcquiredAt: now})
+			})
+
+			if gormet.IsDuplicateKey(err) {
+				// The lease exists and is valid: it can only be extended by its current owner.
+				affected, err = extend(tx, name, owner, 0, now.Add(ttl))
+				if err == nil && affected == 0 {
+					return ErrHeld
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		acquired, err = tx.Get(Lease{Name: name, Owner: owner})
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return acquired, nil
+}
+
+// Renew extends the lease held by the owner for ttl from now, keeping its fencing token.
+//
+// Usage:
+//
+//	if err := leases.Renew(l, time.Minute); errors.Is(err, lease.ErrLost) {
+//		// Stop the work guarded by the lease
+//	}
+//
+// Parameters:
+// - lease: The lease returned by Acquire, updated with the new expiry.
+// - ttl: The new time to live of the lease.
+//
+// Returns:
+// - ErrLost if the lease expired or was taken over, or another error if the table can't be accessed.
+func (m *Manager) Renew(lease *Lease, ttl time.Duration) error {
+	if lease == nil {
+		return errors.New("the lease should not be nil")
+	}
+
+	if err := validate(lease.Name, ttl, lease.Owner); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+
+	affected, err := extend(m.repo, lease.Name, lease.Owner, lease.Token, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrLost
+	}
+
+	lease.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release releases the lease held by the owner, so another owner can acquire it immediately.
+//
+// Returns:
+// - ErrLost if the lease had already expired or was taken over, or another error if the table can't be accessed.
+func (m *Manager) Release(lease *Lease) error {
+	if lease == nil {
+		return errors.New("the lease should not be nil")
+	}
+
+	now := time.Now()
+
+	// The record is kept, expired, so the fencing token of the next holder is still incremented.
+	affected, err := m.repo.UpdateWhere("name = ? AND owner = ? AND token = ? AND expires_at > ?",
+		map[string]interface{}{"expires_at": now}, lease.Name, lease.Owner, lease.Token, now)
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrLost
+	}
+
+	lease.ExpiresAt = now
+	return nil
+}
+
+// Get retrieves the lease with the given name, which may be expired.
+//
+// Returns:
+// - The lease.
+// - gorm.ErrRecordNotFound if the lease was never acquired.
+func (m *Manager) Get(name string) (*Lease, error) {
+	return m.repo.Get(Lease{Name: name})
+}
+
+// Run acquires the lease and runs the function while holding it, renewing the lease in the background
+// at a third of its time to live. When the lease is lost, the context passed to the function is canceled.
+// The lease is released when the function returns.
+//
+// Usage:
+//
+//	err := leases.Run(ctx, "nightly-report", time.Minute, hostname, func(ctx context.Context, l *lease.Lease) error {
+//		return report.Send(ctx, l.Token)
+//	})
+//
+// Parameters:
+// - ctx: Cancels the function, which should return promptly.
+// - name: The name of the lease.
+// - ttl: The time to live of the lease.
+// - owner: Identifies the holder.
+// - fn: The function guarded by the lease; it receives the lease to use its fencing token.
+//
+// Returns:
+// - The error of the function.
+// - ErrHeld if the lease is held by another owner, or ErrLost if the lease was lost while the function ran.
+func (m *Manager) Run(ctx context.Context, name string, ttl time.Duration, owner string, fn func(ctx context.Context, lease *Lease) error) error {
+	if fn == nil {
+		return errors.New("the function should not be nil")
+	}
+
+	lease, err := m.Acquire(name, ttl, owner)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The keeper renews its own copy, so the lease passed to the function is never written concurrently.
+	kept := *lease
+	lost := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		m.keep(runCtx, &kept, ttl, lost, cancel)
+	}()
+
+	err = fn(runCtx, lease)
+
+	cancel()
+	<-stopped
+
+	select {
+	case <-lost:
+		if err == nil || errors.Is(err, context.Canceled) {
+			return ErrLost
+		}
+
+		return err
+	default:
+	}
+
+	if releaseErr := m.Release(&kept); releaseErr != nil && err == nil {
+		return releaseErr
+	}
+
+	return err
+}
+
+// keep renews the lease until the context is canceled, closing lost and canceling the context if it can't.
+func (m *Manager) keep(ctx context.Context, lease *Lease, ttl time.Duration, lost chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Transient errors are retried on the next tick, as long as the lease has not expired.
+			if err := m.Renew(lease, ttl); errors.Is(err, ErrLost) || (err != nil && lease.Expired()) {
+				close(lost)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// extend sets the expiry of a valid lease held by the owner, with the given token when not zero.
+func extend(repo *gormet.Repository[Lease], name string, owner string, token int64, expiresAt time.Time) (int64, error) {
+	query := "name = ? AND owner = ? AND expires_at > ?"
+	args := []interface{}{name, owner, time.Now()}
+
+	if token != 0 {
+		query += " AND token = ?"
+		args = append(args, token)
+	}
+
+	return repo.UpdateWhere(query, map[string]interface{}{"expires_at": expiresAt}, args...)
+}
+
+// validate checks the arguments identifying a lease.
+func validate(name string, ttl time.Duration, owner string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("the lease name should not be empty")
+	}
+
+	if strings.TrimSpace(owner) == "" {
+		return errors.New("the lease owner should not be empty")
+	}
+
+	if ttl < MinTTL {
+		return fmt.Errorf("the lease ttl should be at least %v: %v", MinTTL, ttl)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gsdenys/gormet"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func getGormConnection(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("./database.db"), &gorm.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, AutoMigrate(db))
+
+	return db
+}
+
+func getManager(t *testing.T, db *gorm.DB) *Manager {
+	policy := gormet.DefaultRetryPolicy()
+	policy.MaxAttempts = 50
+	policy.InitialBackoff = time.Millisecond
+
+	manager, err := New(db, gormet.WithRetry(policy))
+	assert.Nil(t, err)
+
+	return manager
+}
+
+func TestManager_Acquire(t *testing.T) {
+	leases := getManager(t, getGormConnection(t))
+
+	t.Run("New lease", func(t *testing.T) {
+		name := uuid.NewString()
+
+		l, err := leases.Acquire(name, time.Minute, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, "a", l.Owner)
+		assert.Equal(t, int64(1), l.Token)
+		assert.False(t, l.Expired())
+	})
+
+	t.Run("Held by another owner", func(t *testing.T) {
+		name := uuid.NewString()
+
+		_, err := leases.Acquire(name, time.Minute, "a")
+		assert.Nil(t, err)
+
+		l, err := leases.Acquire(name, time.Minute, "b")
+		assert.Nil(t, l)
+		assert.Equal(t, ErrHeld, err)
+	})
+
+	t.Run("Reacquired by the same owner", func(t *testing.T) {
+		name := uuid.NewString()
+
+		first, err := leases.Acquire(name, time.Second, "a")
+		assert.Nil(t, err)
+
+		second, err := leases.Acquire(name, time.Minute, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, first.Token, second.Token)
+		assert.True(t, second.ExpiresAt.After(first.ExpiresAt))
+	})
+
+	t.Run("Expired lease taken over", func(t *testing.T) {
+		name := uuid.NewString()
+
+		first, err := leases.Acquire(name, 20*time.Millisecond, "a")
+		assert.Nil(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+
+		second, err := leases.Acquire(name, time.Minute, "b")
+		assert.Nil(t, err)
+		assert.Equal(t, "b", second.Owner)
+		assert.Equal(t, first.Token+1, second.Token)
+
+		assert.Equal(t, ErrLost, leases.Renew(first, time.Minute))
+		assert.Equal(t, ErrLost, leases.Release(first))
+	})
+
+	t.Run("Concurrent owners", func(t *testing.T) {
+		name := uuid.NewString()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		winners := 0
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := leases.Acquire(name, time.Minute, uuid.NewString())
+				if err == nil {
+					mu.Lock()
+					winners++
+					mu.Unlock()
+				} else {
+					assert.Equal(t, ErrHeld, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+		assert.Equal(t, 1, winners)
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		_, err := leases.Acquire(" ", time.Minute, "a")
+		assert.Equal(t, "the lease name should not be empty", err.Error())
+
+		_, err = leases.Acquire("name", time.Minute, "")
+		assert.Equal(t, "the lease owner should not be empty", err.Error())
+
+		_, err = leases.Acquire("name", 0, "a")
+		assert.NotNil(t, err)
+
+		_, err = leases.Acquire("name", time.Millisecond, "a")
+		assert.Equal(t, "the lease ttl should be at least 10ms: 1ms", err.Error())
+
+		err = leases.Run(context.Background(), "name", time.Nanosecond, "a", func(ctx context.Context, l *Lease) error {
+			return nil
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestManager_RenewRelease(t *testing.T) {
+	leases := getManager(t, getGormConnection(t))
+
+	t.Run("Renew", func(t *testing.T) {
+		l, err := leases.Acquire(uuid.NewString(), 50*time.Millisecond, "a")
+		assert.Nil(t, err)
+
+		assert.Nil(t, leases.Renew(l, time.Minute))
+		time.Sleep(60 * time.Millisecond)
+
+		stored, err := leases.Get(l.Name)
+		assert.Nil(t, err)
+		assert.False(t, stored.Expired())
+		assert.Equal(t, l.Token, stored.Token)
+	})
+
+	t.Run("Release keeps the fencing token increasing", func(t *testing.T) {
+		name := uuid.NewString()
+
+		first, err := leases.Acquire(name, time.Minute, "a")
+		assert.Nil(t, err)
+		assert.Nil(t, leases.Release(first))
+		assert.True(t, first.Expired())
+
+		assert.Equal(t, ErrLost, leases.Release(first))
+
+		second, err := leases.Acquire(name, time.Minute, "b")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), second.Token)
+	})
+
+	t.Run("Nil lease", func(t *testing.T) {
+		assert.NotNil(t, leases.Renew(nil, time.Minute))
+		assert.NotNil(t, leases.Release(nil))
+	})
+
+	t.Run("Unknown lease", func(t *testing.T) {
+		_, err := leases.Get(uuid.NewString())
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestManager_Run(t *testing.T) {
+	db := getGormConnection(t)
+	leases := getManager(t, db)
+	ctx := context.Background()
+
+	t.Run("Runs and releases", func(t *testing.T) {
+		name := uuid.NewString()
+
+		err := leases.Run(ctx, name, 30*time.Millisecond, "a", func(ctx context.Context, l *Lease) error {
+			// Outlive the ttl, so the lease is renewed in the background.
+			time.Sleep(80 * time.Millisecond)
+
+			_, err := leases.Acquire(name, time.Minute, "b")
+			assert.Equal(t, ErrHeld, err)
+
+			return ctx.Err()
+		})
+		assert.Nil(t, err)
+
+		stored, err := leases.Get(name)
+		assert.Nil(t, err)
+		assert.True(t, stored.Expired())
+	})
+
+	t.Run("Held by another owner", func(t *testing.T) {
+		name := uuid.NewString()
+
+		_, err := leases.Acquire(name, time.Minute, "a")
+		assert.Nil(t, err)
+
+		called := false
+		err = leases.Run(ctx, name, time.Minute, "b", func(ctx context.Context, l *Lease) error {
+			called = true
+			return nil
+		})
+
+		assert.Equal(t, ErrHeld, err)
+		assert.False(t, called)
+	})
+
+	t.Run("Lost lease cancels the function", func(t *testing.T) {
+		name := uuid.NewString()
+
+		err := leases.Run(ctx, name, 30*time.Millisecond, "a", func(ctx context.Context, l *Lease) error {
+			// Take the lease over, as if it had expired while the replica was paused.
+			assert.Nil(t, db.Model(&Lease{}).Where("name = ?", name).
+				Updates(map[string]interface{}{"owner": "b", "token": l.Token + 1}).Error)
+
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		assert.Equal(t, ErrLost, err)
+	})
+
+	t.Run("Error of the function", func(t *testing.T) {
+		failure := errors.New("failure")
+
+		err := leases.Run(ctx, uuid.NewString(), time.Minute, "a", func(ctx context.Context, l *Lease) error {
+			return failure
+		})
+
+		assert.Equal(t, failure, err)
+		assert.NotNil(t, leases.Run(ctx, "name", time.Minute, "a", nil))
+	})
+}
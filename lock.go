@@ -0,0 +1,173 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockMode is the strength of the row locks taken by GetByIdForUpdate, GetForShare and SearchLock.
+type LockMode int
+
+const (
+	LockForUpdate LockMode = iota // Exclusive lock, blocking the other writers and lockers (FOR UPDATE).
+	LockForShare                  // Shared lock, blocking the writers only (FOR SHARE).
+)
+
+// LockWait defines what a lock request does when a row is already locked by another transaction.
+type LockWait int
+
+const (
+	LockWaitBlock  LockWait = iota // Wait for the other transaction to release the lock.
+	LockSkipLocked                 // Skip the locked rows (SKIP LOCKED).
+	LockNoWait                     // Fail immediately (NOWAIT).
+)
+
+// lockConfig holds the row lock requested by a read operation.
+type lockConfig struct {
+	mode LockMode
+	wait LockWait
+}
+
+// SearchLock locks the rows returned by the search until the end of the transaction. The search must
+// run on the repository passed to Transaction, or on one created from a GORM transaction.
+//
+// The lock is applied as a FOR UPDATE or FOR SHARE clause, supported by PostgreSQL and MySQL. SQLite has
+// no row locks and serializes the write transactions instead, so the option is a no-op there, while SQL
+// Server is not supported and the search fails. The option is ignored by MemoryRepository.
+//
+// Usage:
+//
+//	err := repo.Transaction(func(tx *gormet.Repository[Job]) error {
+//		jobs, err := tx.SearchAll("status = ?", "pending", gormet.SearchLock(gormet.LockForUpdate, gormet.LockSkipLocked))
+//		...
+//	})
+func SearchLock(mode LockMode, wait LockWait) SearchOption {
+	return func(c *searchConfig) error {
+		if mode < LockForUpdate || mode > LockForShare {
+			return fmt.Errorf("unknown lock mode: %d", mode)
+		}
+
+		if wait < LockWaitBlock || wait > LockNoWait {
+			return fmt.Errorf("unknown lock wait: %d", wait)
+		}
+
+		c.lock = &lockConfig{mode: mode, wait: wait}
+		return nil
+	}
+}
+
+// GetByIdForUpdate retrieves the entity with the given ID and locks it exclusively until the end of the
+// transaction, so it can be read, modified and updated without concurrent changes. The cache is bypassed.
+// The locking support of each dialect is described in SearchLock.
+//
+// Usage:
+//
+//	err := repo.Transaction(func(tx *gormet.Repository[Account]) error {
+//		account, err := tx.GetByIdForUpdate(id)
+//		if err != nil {
+//			return err
+//		}
+//
+//		account.Balance -= amount
+//		return tx.Update(account)
+//	})
+//
+// Parameters:
+// - id: The unique identifier of the entity.
+// - wait: Optional behavior when the entity is already locked, LockWaitBlock by default.
+//
+// Returns:
+// - A pointer to the retrieved entity.
+// - An error if the repository is not bound to a transaction, if the dialect does not support locks,
+// or if the retrieval operation encounters any issues, including if the provided id is nil.
+func (r *Repository[T]) GetByIdForUpdate(id interface{}, wait ...LockWait) (*T, error) {
+	if id == nil {
+		return nil, errors.New("the id should not be nil")
+	}
+
+	return r.getLocked(LockForUpdate, wait, fmt.Sprintf("%s = ?", r.pkName), id)
+}
+
+// GetByIdForShare retrieves the entity with the given ID and locks it against concurrent changes until the
+// end of the transaction, while still allowing other transactions to read and share-lock it.
+// See GetByIdForUpdate.
+func (r *Repository[T]) GetByIdForShare(id interface{}, wait ...LockWait) (*T, error) {
+	if id == nil {
+		return nil, errors.New("the id should not be nil")
+	}
+
+	return r.getLocked(LockForShare, wait, fmt.Sprintf("%s = ?", r.pkName), id)
+}
+
+// GetForUpdate retrieves a single entity matching the non-zero fields of the filter, as Get does, and
+// locks it exclusively until the end of the transaction. See GetByIdForUpdate.
+func (r *Repository[T]) GetForUpdate(entity T, wait ...LockWait) (*T, error) {
+	return r.getLocked(LockForUpdate, wait, entity)
+}
+
+// GetForShare retrieves a single entity matching the non-zero fields of the filter, as Get does, and
+// locks it against concurrent changes until the end of the transaction. See GetByIdForShare.
+func (r *Repository[T]) GetForShare(entity T, wait ...LockWait) (*T, error) {
+	return r.getLocked(LockForShare, wait, entity)
+}
+
+// getLocked retrieves the first entity matching the conditions with a row lock.
+func (r *Repository[T]) getLocked(mode LockMode, wait []LockWait, conds ...interface{}) (*T, error) {
+	lock := &lockConfig{mode: mode}
+
+	if len(wait) > 0 {
+		lock.wait = wait[0]
+	}
+
+	locking, err := r.lockClause(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	retrievedEntity := new(T)
+
+	err = r.retry(func() error {
+		return r.scoped(r.db).Clauses(locking).First(retrievedEntity, conds...).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.track(retrievedEntity)
+
+	return retrievedEntity, nil
+}
+
+// lockClause builds the locking clause of a read operation, checking that the repository is bound
+// to a transaction and that the dialect supports row locks.
+func (r *Repository[T]) lockClause(lock *lockConfig) (clause.Locking, error) {
+	if _, ok := r.db.Statement.ConnPool.(gorm.TxCommitter); !ok && !r.inTx {
+		return clause.Locking{}, errors.New("row locks require a transaction")
+	}
+
+	if dialect := r.db.Dialector.Name(); dialect == "sqlserver" {
+		return clause.Locking{}, fmt.Errorf("row locks are not supported by the %s dialect", dialect)
+	}
+
+	locking := clause.Locking{Strength: "UPDATE"}
+
+	if lock.mode == LockForShare {
+		locking.Strength = "SHARE"
+	}
+
+	switch lock.wait {
+	case LockWaitBlock:
+	case LockSkipLocked:
+		locking.Options = "SKIP LOCKED"
+	case LockNoWait:
+		locking.Options = "NOWAIT"
+	default:
+		return clause.Locking{}, fmt.Errorf("unknown lock wait: %d", lock.wait)
+	}
+
+	return locking, nil
+}
@@ -0,0 +1,133 @@
+package gormet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type testLock struct {
+	gorm.Model
+	Name    string `json:"name"`
+	Balance int    `json:"balance"`
+}
+
+func TestRepository_Lock(t *testing.T) {
+	db := getGormConnection(t, &testLock{})
+
+	repo, err := New[testLock](db)
+	assert.Nil(t, err)
+
+	entity := &testLock{Name: uuid.NewString(), Balance: 10}
+	assert.Nil(t, repo.Create(entity))
+
+	t.Run("Read modify write", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testLock]) error {
+			locked, err := tx.GetByIdForUpdate(entity.ID)
+			if err != nil {
+				return err
+			}
+
+			locked.Balance -= 3
+			return tx.Update(locked)
+		})
+		assert.Nil(t, err)
+
+		stored, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 7, stored.Balance)
+	})
+
+	t.Run("Get variants", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testLock]) error {
+			shared, err := tx.GetByIdForShare(entity.ID, LockNoWait)
+			assert.Nil(t, err)
+			assert.Equal(t, entity.Name, shared.Name)
+
+			shared, err = tx.GetForShare(testLock{Name: entity.Name})
+			assert.Nil(t, err)
+			assert.Equal(t, entity.ID, shared.ID)
+
+			locked, err := tx.GetForUpdate(testLock{Name: entity.Name}, LockSkipLocked)
+			assert.Nil(t, err)
+			assert.Equal(t, entity.ID, locked.ID)
+
+			_, err = tx.GetByIdForUpdate(nil)
+			assert.Equal(t, "the id should not be nil", err.Error())
+
+			_, err = tx.GetByIdForUpdate(uint(999999))
+			assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+			return nil
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Search lock", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testLock]) error {
+			entities, err := tx.SearchAll("name = ?", entity.Name, SearchLock(LockForUpdate, LockSkipLocked))
+			assert.Len(t, entities, 1)
+
+			return err
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Repository created from a transaction", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			txRepo, err := New[testLock](tx)
+			if err != nil {
+				return err
+			}
+
+			_, err = txRepo.GetByIdForUpdate(entity.ID)
+			return err
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Transaction required", func(t *testing.T) {
+		_, err := repo.GetByIdForUpdate(entity.ID)
+		assert.Equal(t, "row locks require a transaction", err.Error())
+
+		_, err = repo.GetForShare(testLock{Name: entity.Name})
+		assert.NotNil(t, err)
+
+		_, err = repo.Search(1, "name = ?", entity.Name, SearchLock(LockForShare, LockWaitBlock))
+		assert.Equal(t, "invalid search option: row locks require a transaction", err.Error())
+	})
+
+	t.Run("Invalid lock", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testLock]) error {
+			_, err := tx.SearchAll("name = ?", entity.Name, SearchLock(LockMode(42), LockWaitBlock))
+			assert.NotNil(t, err)
+
+			_, err = tx.SearchAll("name = ?", entity.Name, SearchLock(LockForUpdate, LockWait(42)))
+			assert.NotNil(t, err)
+
+			_, err = tx.GetByIdForUpdate(entity.ID, LockWait(42))
+			assert.NotNil(t, err)
+
+			return nil
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Lock clause", func(t *testing.T) {
+		err := repo.Transaction(func(tx *Repository[testLock]) error {
+			locking, err := tx.lockClause(&lockConfig{mode: LockForUpdate, wait: LockSkipLocked})
+			assert.Nil(t, err)
+			assert.Equal(t, clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}, locking)
+
+			locking, err = tx.lockClause(&lockConfig{mode: LockForShare, wait: LockNoWait})
+			assert.Nil(t, err)
+			assert.Equal(t, clause.Locking{Strength: "SHARE", Options: "NOWAIT"}, locking)
+
+			return nil
+		})
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,578 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MemoryRepository is an in-memory implementation of RepositoryAPI intended for fast unit tests.
+//
+// It mimics the behavior of Repository: integer primary keys are auto incremented, the unique
+// constraints declared with the gorm `unique` and `uniqueIndex` tags are enforced, entities with
+// a gorm.DeletedAt field are soft deleted according to the SoftDeleteStrategy, and searches follow
+// the same pagination semantics. The criteria supported by Search and SearchAll are described in
+// parseCriteria. The logger, cache, hooks, history and tree options are ignored since no database is involved,
+// as are the SearchSelect, SearchPreload and SearchLock search options.
+type MemoryRepository[T any] struct {
+	PageSize uint           // Define if the size of page
+	pkName   string         // The name of the primary key field.
+	schema   *schema.Schema // The parsed GORM schema of the model type T.
+	config   *config        // The settings assembled from the options passed to NewMemory.
+	latest   string         // The column defining the recency of the entities.
+	search   *searchConfig  // The search options of the current call, nil outside a search.
+	scopes   *memoryScopes  // The predicates of the default and named scopes.
+	unscoped bool           // Whether the default scopes are ignored.
+	store    *memoryStore[T]
+}
+
+// memoryStore holds the entities of a MemoryRepository.
+type memoryStore[T any] struct {
+	mu       sync.RWMutex
+	entities []T   // Stored entities, in insertion order.
+	sequence int64 // Last value assigned to an integer primary key.
+}
+
+// Ensure MemoryRepository implements RepositoryAPI.
+var _ RepositoryAPI[any] = (*MemoryRepository[any])(nil)
+
+// NewMemory creates an in-memory repository for the model type T, accepting the same options as New.
+//
+// Usage:
+// repo, err := gormet.NewMemory[User](gormet.WithPageSize(10))
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+//	service := NewUserService(repo) // service depends on gormet.RepositoryAPI[User]
+//
+// Parameters:
+//   - opts: Optional settings such as page size, default sort and soft delete strategy.
+//
+// Returns:
+// - A pointer to a newly created MemoryRepository for type T if successful.
+// - An error if the model cannot be parsed, has no primary key or if an option is invalid.
+func NewMemory[T any](opts ...Option) (*MemoryRepository[T], error) {
+	sch, err := schema.Parse(new(T), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return nil, fmt.Errorf("data struct parse error: %s", err.Error())
+	}
+
+	var pkName string
+	for _, field := range sch.Fields {
+		if field.PrimaryKey {
+			pkName = field.DBName
+			break
+		}
+	}
+
+	if pkName == "" {
+		return nil, fmt.Errorf("impossible to retrieve primary key: no primary key found")
+	}
+
+	cfg, err := newConfig(sch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := &memoryScopes{}
+
+	err = newMemoryScopes(scopes, cfg, func(s scope) (predicate, error) {
+		return parseCriteria(sch, s.query, s.args...)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid option: %v", err)
+	}
+
+	return &MemoryRepository[T]{
+		PageSize: cfg.pageSize,
+		pkName:   pkName,
+		schema:   sch,
+		config:   cfg,
+		latest:   latestColumn(sch, cfg.latest, pkName),
+		scopes:   scopes,
+		store:    &memoryStore[T]{},
+	}, nil
+}
+
+// Get retrieves the first entity, ordered by primary key, whose fields are equal to the
+// non-zero fields of the given entity.
+func (m *MemoryRepository[T]) Get(entity T) (*T, error) {
+	match, err := parseCriteria(m.schema, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.first(match, m.pkName)
+}
+
+// GetById retrieves the entity with the given primary key.
+func (m *MemoryRepository[T]) GetById(id interface{}) (*T, error) {
+	if id == nil {
+		return nil, errors.New("the id should not be nil")
+	}
+
+	return m.first(m.pkPredicate(id), m.pkName)
+}
+
+// GetLatest retrieves the latest entity, using the same recency column as Repository.
+func (m *MemoryRepository[T]) GetLatest() (*T, error) {
+	return m.first(func(reflect.Value) bool { return true }, m.recencyOrder(true))
+}
+
+// GetOldest retrieves the oldest entity, using the same recency column as Repository.
+func (m *MemoryRepository[T]) GetOldest() (*T, error) {
+	return m.first(func(reflect.Value) bool { return true }, m.recencyOrder(false))
+}
+
+// GetLatestN retrieves the n latest entities matching the given criteria, the latest first.
+func (m *MemoryRepository[T]) GetLatestN(n uint, query interface{}, args ...interface{}) ([]T, error) {
+	match, err := parseCriteria(m.schema, query, args...)
+	if err != nil {
+		return []T{}, err
+	}
+
+	entities := m.filter(match, m.recencyOrder(true))
+
+	return entities[:min(int(n), len(entities))], nil
+}
+
+// Create stores a copy of the entity, assigning the auto incremented primary key, the creation
+// and update times and the default values, as GORM does.
+func (m *MemoryRepository[T]) Create(entity *T) error {
+	if entity == nil {
+		return errors.New("the entity should not be nil")
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.insert(entity)
+}
+
+// Update replaces the stored entity that has the same primary key. As GORM's Save, an entity
+// without primary key, or whose primary key is not stored yet, is created. A soft deleted entity
+// can't be updated, so its primary key is never stored twice.
+func (m *MemoryRepository[T]) Update(entity *T) error {
+	if entity == nil {
+		return errors.New("the entity should not be nil")
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	rv := reflect.ValueOf(entity).Elem()
+	pk, zero := m.pkField().ValueOf(context.Background(), rv)
+
+	idx := -1
+	if !zero {
+		idx = m.indexOf(pk)
+	}
+
+	if idx < 0 {
+		return m.insert(entity)
+	}
+
+	if !m.visible(reflect.ValueOf(&m.store.entities[idx]).Elem()) {
+		return fmt.Errorf("no register found")
+	}
+
+	m.setAutoTime(rv, false)
+
+	if err := m.checkConstraints(rv, idx); err != nil {
+		return err
+	}
+
+	m.store.entities[idx] = *entity
+
+	return nil
+}
+
+// Delete removes the stored entity that has the same primary key as the given entity.
+func (m *MemoryRepository[T]) Delete(entity *T) error {
+	if entity == nil {
+		return errors.New("the entity should not be nil")
+	}
+
+	pk, _ := m.pkField().ValueOf(context.Background(), reflect.ValueOf(entity).Elem())
+
+	return m.remove(pk)
+}
+
+// DeleteById removes the stored entity with the given primary key.
+func (m *MemoryRepository[T]) DeleteById(id interface{}) error {
+	if id == nil {
+		return errors.New("the ID should not be nil")
+	}
+
+	return m.remove(id)
+}
+
+// Search performs a paginated search for entities matching the given criteria.
+func (m *MemoryRepository[T]) Search(page uint, query interface{}, args ...interface{}) (Pagination[T], error) {
+	return m.SearchWith(page, nil, query, args...)
+}
+
+// SearchWith performs a paginated search as Search, customized by the given search options.
+func (m *MemoryRepository[T]) SearchWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error) {
+	repo, args, err := m.withSearchOptions(args, opts...)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	var pageSize uint = repo.pageSize()
+	var offset int = getOffset(page, pageSize)
+	var limit int = getLimit(pageSize)
+
+	entities, err := repo.SearchAll(query, args...)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	count := int64(len(entities))
+
+	if offset > 0 {
+		entities = entities[min(offset, len(entities)):]
+	}
+
+	if limit >= 0 {
+		entities = entities[:min(limit, len(entities))]
+	}
+
+	return Pagination[T]{
+		Response: newResponse(entities, count, page, pageSize),
+		criteria: query,
+	}, nil
+}
+
+// SearchSlice performs a paginated search for entities matching the given criteria, without the total count.
+func (m *MemoryRepository[T]) SearchSlice(page uint, query interface{}, args ...interface{}) (Slice[T], error) {
+	return m.SearchSliceWith(page, nil, query, args...)
+}
+
+// SearchSliceWith performs a paginated search as SearchSlice, customized by the given search options.
+func (m *MemoryRepository[T]) SearchSliceWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Slice[T], error) {
+	repo, args, err := m.withSearchOptions(args, opts...)
+	if err != nil {
+		return Slice[T]{}, err
+	}
+
+	var pageSize uint = repo.pageSize()
+	var offset int = getOffset(page, pageSize)
+	var limit int = getLimit(pageSize)
+
+	entities, err := repo.SearchAll(query, args...)
+	if err != nil {
+		return Slice[T]{}, err
+	}
+
+	if offset > 0 {
+		entities = entities[min(offset, len(entities)):]
+	}
+
+	if limit >= 0 {
+		entities = entities[:min(limit+1, len(entities))]
+	}
+
+	return newSlice(entities, page, pageSize), nil
+}
+
+// SearchUnpaged retrieves all the entities matching the given criteria as a single page.
+func (m *MemoryRepository[T]) SearchUnpaged(query interface{}, args ...interface{}) (Pagination[T], error) {
+	return m.SearchUnpagedWith(nil, query, args...)
+}
+
+// SearchUnpagedWith retrieves all the matching entities as SearchUnpaged, customized by the given search options.
+func (m *MemoryRepository[T]) SearchUnpagedWith(opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error) {
+	entities, err := m.SearchAllWith(opts, query, args...)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	return Pagination[T]{
+		Response: newResponse(entities, int64(len(entities)), 1, 0),
+		criteria: query,
+	}, nil
+}
+
+// Count returns the number of entities matching the given criteria.
+func (m *MemoryRepository[T]) Count(query interface{}, args ...interface{}) (int64, error) {
+	return m.CountWith(nil, query, args...)
+}
+
+// CountWith returns the number of matching entities as Count, customized by the given search options.
+func (m *MemoryRepository[T]) CountWith(opts []SearchOption, query interface{}, args ...interface{}) (int64, error) {
+	entities, err := m.SearchAllWith(opts, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(entities)), nil
+}
+
+// SearchAll retrieves all the entities matching the given criteria.
+func (m *MemoryRepository[T]) SearchAll(query interface{}, args ...interface{}) ([]T, error) {
+	return m.SearchAllWith(nil, query, args...)
+}
+
+// SearchAllWith retrieves all the matching entities as SearchAll, customized by the given search options.
+func (m *MemoryRepository[T]) SearchAllWith(opts []SearchOption, query interface{}, args ...interface{}) ([]T, error) {
+	repo, args, err := m.withSearchOptions(args, opts...)
+	if err != nil {
+		return []T{}, err
+	}
+
+	match, err := parseCriteria(repo.schema, query, args...)
+	if err != nil {
+		return []T{}, err
+	}
+
+	return repo.filter(match, repo.config.searchSort(repo.search)), nil
+}
+
+// pageSize returns the page size used by searches, clamped to the configured maximum.
+func (m *MemoryRepository[T]) pageSize() uint {
+	return m.config.searchPageSize(m.search, m.PageSize)
+}
+
+// recencyOrder returns the order clause by the recency column, then by primary key.
+func (m *MemoryRepository[T]) recencyOrder(desc bool) string {
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+
+	if m.latest == m.pkName {
+		return m.pkName + direction
+	}
+
+	return m.latest + direction + ", " + m.pkName + direction
+}
+
+// pkField returns the schema field of the primary key.
+func (m *MemoryRepository[T]) pkField() *schema.Field {
+	return m.schema.LookUpField(m.pkName)
+}
+
+// pkPredicate returns a predicate matching the entity with the given primary key.
+func (m *MemoryRepository[T]) pkPredicate(id interface{}) predicate {
+	field := m.pkField()
+
+	return func(entity reflect.Value) bool {
+		result, ok := compareValues(fieldValue(field, entity), id)
+		return ok && result == 0
+	}
+}
+
+// first returns a copy of the first visible entity matching the predicate in the given order.
+func (m *MemoryRepository[T]) first(match predicate, order string) (*T, error) {
+	entities := m.filter(match, order)
+
+	if len(entities) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &entities[0], nil
+}
+
+// filter returns copies of the visible entities matching the predicate, sorted by the given order.
+func (m *MemoryRepository[T]) filter(match predicate, order string) []T {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	entities := make([]T, 0)
+
+	for i := range m.store.entities {
+		rv := reflect.ValueOf(&m.store.entities[i]).Elem()
+
+		if m.visible(rv) && m.inScope(rv) && match(rv) {
+			entities = append(entities, m.store.entities[i])
+		}
+	}
+
+	if order != "" {
+		sortEntities(m.schema, entities, order)
+	}
+
+	return entities
+}
+
+// visible reports whether the entity has not been soft deleted.
+func (m *MemoryRepository[T]) visible(entity reflect.Value) bool {
+	field := softDeleteField(m.schema)
+	return field == nil || normalizeValue(fieldValue(field, entity)) == nil
+}
+
+// indexOf returns the position of the entity with the given primary key in the store, or -1.
+// The caller must hold the store lock.
+func (m *MemoryRepository[T]) indexOf(id interface{}) int {
+	match := m.pkPredicate(id)
+
+	for i := range m.store.entities {
+		if match(reflect.ValueOf(&m.store.entities[i]).Elem()) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// insert assigns the generated values to the entity and stores a copy of it.
+// The caller must hold the store lock.
+func (m *MemoryRepository[T]) insert(entity *T) error {
+	ctx := context.Background()
+	rv := reflect.ValueOf(entity).Elem()
+	field := m.pkField()
+
+	for _, f := range m.schema.Fields {
+		if f.DefaultValueInterface != nil {
+			if _, zero := f.ValueOf(ctx, rv); zero {
+				if err := f.Set(ctx, rv, f.DefaultValueInterface); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if pk, zero := field.ValueOf(ctx, rv); zero && isInteger(field) {
+		m.store.sequence++
+		if err := field.Set(ctx, rv, m.store.sequence); err != nil {
+			return err
+		}
+	} else if n, ok := normalizeValue(pk).(int64); ok && n > m.store.sequence {
+		m.store.sequence = n
+	}
+
+	m.setAutoTime(rv, true)
+
+	if err := m.checkConstraints(rv, -1); err != nil {
+		return err
+	}
+
+	m.store.entities = append(m.store.entities, *entity)
+
+	return nil
+}
+
+// remove deletes the entity with the given primary key according to the soft delete strategy.
+func (m *MemoryRepository[T]) remove(id interface{}) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	idx := m.indexOf(id)
+	if idx < 0 || !m.visible(reflect.ValueOf(&m.store.entities[idx]).Elem()) {
+		return fmt.Errorf("no register found")
+	}
+
+	if field := softDeleteField(m.schema); field != nil && m.config.softDelete != SoftDeleteNever {
+		rv := reflect.ValueOf(&m.store.entities[idx]).Elem()
+		return field.Set(context.Background(), rv, time.Now())
+	}
+
+	m.store.entities = append(m.store.entities[:idx], m.store.entities[idx+1:]...)
+
+	return nil
+}
+
+// setAutoTime sets the fields tracking the creation and update times, as GORM does.
+func (m *MemoryRepository[T]) setAutoTime(entity reflect.Value, creating bool) {
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, field := range m.schema.Fields {
+		if field.AutoUpdateTime > 0 {
+			_ = field.Set(ctx, entity, now)
+			continue
+		}
+
+		if creating && field.AutoCreateTime > 0 {
+			if _, zero := field.ValueOf(ctx, entity); zero {
+				_ = field.Set(ctx, entity, now)
+			}
+		}
+	}
+}
+
+// checkConstraints verifies the not null and unique constraints of the entity against the
+// stored entities, ignoring the one at position skip. The caller must hold the store lock.
+func (m *MemoryRepository[T]) checkConstraints(entity reflect.Value, skip int) error {
+	for _, field := range m.schema.Fields {
+		if field.NotNull && field.DBName != "" && field.HasDefaultValue && field.DefaultValueInterface == nil {
+			if _, zero := field.ValueOf(context.Background(), entity); zero {
+				return fmt.Errorf("NOT NULL constraint failed: %s.%s", m.schema.Table, field.DBName)
+			}
+		}
+	}
+
+	for _, fields := range m.uniqueConstraints() {
+		for i := range m.store.entities {
+			if i == skip {
+				continue
+			}
+
+			if sameValues(fields, entity, reflect.ValueOf(&m.store.entities[i]).Elem()) {
+				columns := make([]string, len(fields))
+				for j, field := range fields {
+					columns[j] = fmt.Sprintf("%s.%s", m.schema.Table, field.DBName)
+				}
+
+				return fmt.Errorf("%w: UNIQUE constraint failed: %s", gorm.ErrDuplicatedKey, strings.Join(columns, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// uniqueConstraints returns the sets of fields that must be unique: the primary key, the
+// fields tagged as unique and the fields of each unique index.
+func (m *MemoryRepository[T]) uniqueConstraints() [][]*schema.Field {
+	constraints := [][]*schema.Field{m.schema.PrimaryFields}
+
+	for _, field := range m.schema.Fields {
+		if field.Unique && !field.PrimaryKey {
+			constraints = append(constraints, []*schema.Field{field})
+		}
+	}
+
+	for _, index := range m.schema.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+
+		fields := make([]*schema.Field, len(index.Fields))
+		for i, option := range index.Fields {
+			fields[i] = option.Field
+		}
+
+		constraints = append(constraints, fields)
+	}
+
+	return constraints
+}
+
+// sameValues reports whether two entities have equal, non-null values for all the given fields.
+func sameValues(fields []*schema.Field, a reflect.Value, b reflect.Value) bool {
+	for _, field := range fields {
+		result, ok := compareValues(fieldValue(field, a), fieldValue(field, b))
+		if !ok || result != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isInteger reports whether the field holds an integer value.
+func isInteger(field *schema.Field) bool {
+	return field.DataType == schema.Int || field.DataType == schema.Uint
+}
@@ -0,0 +1,486 @@
+package gormet
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// predicate reports whether an entity, given as the reflected struct value, matches a criteria.
+type predicate func(entity reflect.Value) bool
+
+// condition is a single comparison between an entity field and a list of values.
+type condition struct {
+	field  *schema.Field
+	op     string
+	values []interface{}
+}
+
+var (
+	// andSeparator splits a criteria string into its conditions.
+	andSeparator = regexp.MustCompile(`(?i)\s+and\s+`)
+
+	// comparisonClause matches conditions such as "name = ?", "age >= 18" or "id IN (?)".
+	comparisonClause = regexp.MustCompile(`(?i)^\s*([\w."` + "`" + `]+)\s*(=|!=|<>|>=|<=|>|<|not\s+like|like|not\s+in|in)\s*(\(\s*\?\s*\)|\?|'[^']*'|-?\d+(?:\.\d+)?|true|false)\s*$`)
+
+	// nullClause matches conditions such as "deleted_at IS NULL".
+	nullClause = regexp.MustCompile(`(?i)^\s*([\w."` + "`" + `]+)\s+is\s+(not\s+)?null\s*$`)
+)
+
+// parseCriteria converts the query and arguments accepted by Search into a predicate.
+//
+// The supported criteria are a nil or empty query, a struct of type T (non-zero fields are
+// compared for equality, as GORM does), a map of column names to values and a string made of
+// conditions joined by AND using the operators =, !=, <>, >, >=, <, <=, LIKE, NOT LIKE, IN,
+// NOT IN, IS NULL and IS NOT NULL. A Specification of any form is supported as well.
+func parseCriteria(sch *schema.Schema, query interface{}, args ...interface{}) (predicate, error) {
+	var conditions []condition
+	var err error
+
+	switch q := query.(type) {
+	case nil:
+	case specification:
+		if q.model() != sch.ModelType {
+			return nil, foreignSpecification(q, sch)
+		}
+
+		if len(args) > 0 {
+			return nil, errors.New("a specification takes no arguments")
+		}
+
+		match, err := q.predicate(sch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid specification: %v", err)
+		}
+
+		return match, nil
+	case string:
+		conditions, err = parseStringCriteria(sch, q, args)
+	case map[string]interface{}:
+		conditions, err = parseMapCriteria(sch, q)
+	default:
+		conditions, err = parseStructCriteria(sch, query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(entity reflect.Value) bool {
+		for _, c := range conditions {
+			if !c.match(entity) {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
+// parseStringCriteria parses a string made of conditions joined by AND.
+func parseStringCriteria(sch *schema.Schema, query string, args []interface{}) ([]condition, error) {
+	var conditions []condition
+
+	if strings.TrimSpace(query) == "" {
+		return conditions, nil
+	}
+
+	for _, clause := range andSeparator.Split(query, -1) {
+		if match := nullClause.FindStringSubmatch(clause); match != nil {
+			field, err := lookUpColumn(sch, match[1])
+			if err != nil {
+				return nil, err
+			}
+
+			op := "IS NULL"
+			if match[2] != "" {
+				op = "IS NOT NULL"
+			}
+
+			conditions = append(conditions, condition{field: field, op: op})
+			continue
+		}
+
+		match := comparisonClause.FindStringSubmatch(clause)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported criteria: %q", strings.TrimSpace(clause))
+		}
+
+		field, err := lookUpColumn(sch, match[1])
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if strings.Contains(match[3], "?") {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("missing argument for criteria: %q", strings.TrimSpace(clause))
+			}
+
+			value, args = args[0], args[1:]
+		} else {
+			value = parseLiteral(match[3])
+		}
+
+		op := strings.ToUpper(strings.Join(strings.Fields(match[2]), " "))
+		if op == "<>" {
+			op = "!="
+		}
+
+		values := []interface{}{value}
+		if op == "IN" || op == "NOT IN" {
+			values = toSlice(value)
+		}
+
+		conditions = append(conditions, condition{field: field, op: op, values: values})
+	}
+
+	if len(args) > 0 {
+		return nil, fmt.Errorf("too many arguments for criteria: %q", query)
+	}
+
+	return conditions, nil
+}
+
+// parseMapCriteria parses a map of column names to values, compared for equality.
+func parseMapCriteria(sch *schema.Schema, query map[string]interface{}) ([]condition, error) {
+	var conditions []condition
+
+	for column, value := range query {
+		field, err := lookUpColumn(sch, column)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case value == nil:
+			conditions = append(conditions, condition{field: field, op: "IS NULL"})
+		case isSlice(value):
+			conditions = append(conditions, condition{field: field, op: "IN", values: toSlice(value)})
+		default:
+			conditions = append(conditions, condition{field: field, op: "=", values: []interface{}{value}})
+		}
+	}
+
+	return conditions, nil
+}
+
+// parseStructCriteria parses a struct of the model type, comparing its non-zero fields for equality.
+func parseStructCriteria(sch *schema.Schema, query interface{}) ([]condition, error) {
+	rv := reflect.Indirect(reflect.ValueOf(query))
+
+	if rv.Kind() != reflect.Struct || rv.Type() != sch.ModelType {
+		return nil, fmt.Errorf("unsupported criteria type: %T", query)
+	}
+
+	var conditions []condition
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		if value, zero := field.ValueOf(context.Background(), rv); !zero {
+			conditions = append(conditions, condition{field: field, op: "=", values: []interface{}{value}})
+		}
+	}
+
+	return conditions, nil
+}
+
+// lookUpColumn finds the schema field of a column, ignoring table prefixes and quotes.
+func lookUpColumn(sch *schema.Schema, column string) (*schema.Field, error) {
+	column = strings.Trim(column, "\"`")
+	if idx := strings.LastIndex(column, "."); idx >= 0 {
+		column = strings.Trim(column[idx+1:], "\"`")
+	}
+
+	field := sch.LookUpField(column)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("unknown column: %q", column)
+	}
+
+	return field, nil
+}
+
+// parseLiteral converts a literal written in a criteria string into a value.
+func parseLiteral(literal string) interface{} {
+	switch strings.ToLower(literal) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if strings.HasPrefix(literal, "'") {
+		return strings.Trim(literal, "'")
+	}
+
+	if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return n
+	}
+
+	f, _ := strconv.ParseFloat(literal, 64)
+	return f
+}
+
+// match reports whether the entity satisfies the condition.
+func (c condition) match(entity reflect.Value) bool {
+	value := normalizeValue(fieldValue(c.field, entity))
+
+	switch c.op {
+	case "IS NULL":
+		return value == nil
+	case "IS NOT NULL":
+		return value != nil
+	case "IN":
+		return containsValue(c.values, value)
+	case "NOT IN":
+		return value != nil && !containsValue(c.values, value)
+	case "LIKE", "NOT LIKE":
+		text, ok := value.(string)
+		pattern, isString := normalizeValue(c.values[0]).(string)
+		if !ok || !isString {
+			return false
+		}
+
+		return likeMatch(pattern, text) == (c.op == "LIKE")
+	}
+
+	result, ok := compareValues(value, c.values[0])
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	default:
+		return result <= 0
+	}
+}
+
+// fieldValue returns the value of a field of the entity.
+func fieldValue(field *schema.Field, entity reflect.Value) interface{} {
+	value, _ := field.ValueOf(context.Background(), entity)
+	return value
+}
+
+// containsValue reports whether the value is equal to one of the values of the list.
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if result, ok := compareValues(value, v); ok && result == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// likeMatch reports whether the text matches a SQL LIKE pattern. As in SQLite, the match is case-insensitive.
+func likeMatch(pattern string, text string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String()).MatchString(text)
+}
+
+// normalizeValue converts a value into int64, float64, string, time.Time or nil, so values of
+// different Go types holding the same database value can be compared.
+func normalizeValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil
+	}
+
+	v = rv.Interface()
+
+	if t, ok := v.(time.Time); ok {
+		return t
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return v
+		}
+
+		return normalizeValue(value)
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		if rv.Bool() {
+			return int64(1)
+		}
+
+		return int64(0)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes())
+		}
+	}
+
+	return v
+}
+
+// compareValues compares two values after normalization, returning -1, 0 or 1 and whether
+// the values are comparable at all.
+func compareValues(a, b interface{}) (int, bool) {
+	a, b = normalizeValue(a), normalizeValue(b)
+
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	if x, ok := a.(time.Time); ok {
+		y, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+
+		return x.Compare(y), true
+	}
+
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+
+	x, okA := toFloat(a)
+	y, okB := toFloat(b)
+
+	if !okA || !okB {
+		return 0, false
+	}
+
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// toFloat converts a normalized value into a float64, parsing numeric strings.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// isSlice reports whether the value is a slice or an array, other than a byte slice.
+func isSlice(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	kind := rv.Kind()
+
+	return (kind == reflect.Slice || kind == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+// toSlice converts a slice or an array into a list of values. Other values become a list of one element.
+func toSlice(v interface{}) []interface{} {
+	if !isSlice(v) {
+		return []interface{}{v}
+	}
+
+	rv := reflect.ValueOf(v)
+	values := make([]interface{}, rv.Len())
+
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+
+	return values
+}
+
+// sortEntities sorts the entities according to an order clause such as "name ASC, id DESC".
+// The order clause must have been validated with validateSort.
+func sortEntities[T any](sch *schema.Schema, entities []T, order string) {
+	type key struct {
+		field *schema.Field
+		desc  bool
+	}
+
+	var keys []key
+	for _, part := range strings.Split(order, ",") {
+		tokens := strings.Fields(part)
+		keys = append(keys, key{
+			field: sch.LookUpField(tokens[0]),
+			desc:  len(tokens) == 2 && strings.EqualFold(tokens[1], "DESC"),
+		})
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		a := reflect.ValueOf(&entities[i]).Elem()
+		b := reflect.ValueOf(&entities[j]).Elem()
+
+		for _, k := range keys {
+			result, _ := compareValues(fieldValue(k.field, a), fieldValue(k.field, b))
+			if result == 0 {
+				continue
+			}
+
+			return (result < 0) != k.desc
+		}
+
+		return false
+	})
+}
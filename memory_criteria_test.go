@@ -0,0 +1,49 @@
+package gormet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_compareValues(t *testing.T) {
+	now := time.Now()
+	count := 3
+
+	tests := []struct {
+		name   string
+		a, b   interface{}
+		result int
+		ok     bool
+	}{
+		{name: "Equal integers of different types", a: uint(3), b: 3, result: 0, ok: true},
+		{name: "Integer and float", a: 2, b: 2.5, result: -1, ok: true},
+		{name: "Integer pointer", a: &count, b: int64(3), result: 0, ok: true},
+		{name: "Numeric string", a: 10, b: "10", result: 0, ok: true},
+		{name: "Invalid numeric string", a: 10, b: `10"; drop`, ok: false},
+		{name: "Strings", a: "b", b: "a", result: 1, ok: true},
+		{name: "Times", a: now, b: now.Add(time.Second), result: -1, ok: true},
+		{name: "Boolean", a: true, b: 1, result: 0, ok: true},
+		{name: "Nil", a: nil, b: 1, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := compareValues(tt.a, tt.b)
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.result, result)
+			}
+		})
+	}
+}
+
+func Test_likeMatch(t *testing.T) {
+	assert.True(t, likeMatch("jo%", "John"))
+	assert.True(t, likeMatch("%oh_", "john"))
+	assert.False(t, likeMatch("jo_", "john"))
+	assert.True(t, likeMatch("a.b%", "A.Bc"))
+	assert.False(t, likeMatch("a.b", "axb"))
+}
@@ -0,0 +1,355 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testMemory struct {
+	gorm.Model
+	Email  string `json:"email" gorm:"uniqueIndex:idx_memory_email_group"`
+	Group  string `json:"group" gorm:"uniqueIndex:idx_memory_email_group"`
+	Name   string `json:"name" gorm:"unique;not null;default:null"`
+	Active bool   `json:"active"`
+	Age    int    `json:"age"`
+}
+
+type testMemoryCode struct {
+	Code string `gorm:"primaryKey"`
+	Name string
+}
+
+func createMemory(t *testing.T, repo *MemoryRepository[testMemory], group string, age int) *testMemory {
+	entity := &testMemory{
+		Name:  uuid.NewString(),
+		Email: fmt.Sprintf("%s@mail.com", uuid.NewString()),
+		Group: group,
+		Age:   age,
+	}
+
+	assert.Nil(t, repo.Create(entity))
+
+	return entity
+}
+
+func TestNewMemory(t *testing.T) {
+	t.Run("Valid model", func(t *testing.T) {
+		repo, err := NewMemory[testMemory](WithPageSize(10))
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(10), repo.PageSize)
+		assert.Equal(t, "id", repo.pkName)
+	})
+
+	t.Run("Model without primary key", func(t *testing.T) {
+		type NoPK struct {
+			Name string
+		}
+
+		repo, err := NewMemory[NoPK]()
+
+		assert.Nil(t, repo)
+		assert.Equal(t, "impossible to retrieve primary key: no primary key found", err.Error())
+	})
+
+	t.Run("Invalid option", func(t *testing.T) {
+		repo, err := NewMemory[testMemory](WithDefaultSort("unknown"))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMemoryRepository_Write(t *testing.T) {
+	repo, err := NewMemory[testMemory]()
+	assert.Nil(t, err)
+
+	t.Run("Create assigns generated values", func(t *testing.T) {
+		first := createMemory(t, repo, "a", 1)
+		second := createMemory(t, repo, "a", 2)
+
+		assert.NotZero(t, first.ID)
+		assert.Equal(t, first.ID+1, second.ID)
+		assert.False(t, first.CreatedAt.IsZero())
+		assert.False(t, first.UpdatedAt.IsZero())
+	})
+
+	t.Run("Create nil entity", func(t *testing.T) {
+		err := repo.Create(nil)
+		assert.Equal(t, "the entity should not be nil", err.Error())
+	})
+
+	t.Run("Unique field violation", func(t *testing.T) {
+		entity := createMemory(t, repo, "b", 1)
+
+		err := repo.Create(&testMemory{Name: entity.Name, Email: "other@mail.com"})
+		assert.True(t, errors.Is(err, gorm.ErrDuplicatedKey))
+	})
+
+	t.Run("Unique index violation", func(t *testing.T) {
+		entity := createMemory(t, repo, "c", 1)
+
+		err := repo.Create(&testMemory{Name: uuid.NewString(), Email: entity.Email, Group: "c"})
+		assert.True(t, errors.Is(err, gorm.ErrDuplicatedKey))
+
+		err = repo.Create(&testMemory{Name: uuid.NewString(), Email: entity.Email, Group: "other"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Not null violation", func(t *testing.T) {
+		err := repo.Create(&testMemory{Email: "nonull@mail.com"})
+		assert.Equal(t, "NOT NULL constraint failed: test_memories.name", err.Error())
+	})
+
+	t.Run("Primary key violation", func(t *testing.T) {
+		codes, err := NewMemory[testMemoryCode]()
+		assert.Nil(t, err)
+
+		assert.Nil(t, codes.Create(&testMemoryCode{Code: "x"}))
+		assert.True(t, errors.Is(codes.Create(&testMemoryCode{Code: "x"}), gorm.ErrDuplicatedKey))
+	})
+
+	t.Run("Update stored entity", func(t *testing.T) {
+		entity := createMemory(t, repo, "d", 1)
+		entity.Age = 42
+
+		assert.Nil(t, repo.Update(entity))
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 42, got.Age)
+	})
+
+	t.Run("Update creates new entity", func(t *testing.T) {
+		entity := &testMemory{Name: uuid.NewString()}
+
+		assert.Nil(t, repo.Update(entity))
+		assert.NotZero(t, entity.ID)
+	})
+
+	t.Run("Delete soft deletes", func(t *testing.T) {
+		entity := createMemory(t, repo, "e", 1)
+
+		assert.Nil(t, repo.Delete(entity))
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, got)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		err = repo.DeleteById(entity.ID)
+		assert.Equal(t, "no register found", err.Error())
+	})
+
+	t.Run("Update soft deleted entity", func(t *testing.T) {
+		entity := createMemory(t, repo, "f", 1)
+		assert.Nil(t, repo.Delete(entity))
+
+		stored := len(repo.store.entities)
+
+		entity.DeletedAt = gorm.DeletedAt{}
+		err := repo.Update(entity)
+		assert.Equal(t, "no register found", err.Error())
+		assert.Equal(t, stored, len(repo.store.entities))
+	})
+
+	t.Run("Delete nil values", func(t *testing.T) {
+		assert.Equal(t, "the entity should not be nil", repo.Delete(nil).Error())
+		assert.Equal(t, "the ID should not be nil", repo.DeleteById(nil).Error())
+	})
+}
+
+func TestMemoryRepository_Get(t *testing.T) {
+	repo, err := NewMemory[testMemory]()
+	assert.Nil(t, err)
+
+	t.Run("Empty repository", func(t *testing.T) {
+		got, err := repo.GetLatest()
+		assert.Nil(t, got)
+		assert.Equal(t, "record not found", err.Error())
+	})
+
+	first := createMemory(t, repo, "a", 1)
+	latest := createMemory(t, repo, "a", 2)
+
+	t.Run("Get by example", func(t *testing.T) {
+		got, err := repo.Get(testMemory{Email: first.Email})
+		assert.Nil(t, err)
+		assert.Equal(t, first.ID, got.ID)
+	})
+
+	t.Run("Get by id", func(t *testing.T) {
+		got, err := repo.GetById(latest.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, latest.Name, got.Name)
+
+		got, err = repo.GetById(fmt.Sprintf("%d", latest.ID) + `"; drop table --`)
+		assert.Nil(t, got)
+		assert.Equal(t, "record not found", err.Error())
+
+		_, err = repo.GetById(nil)
+		assert.Equal(t, "the id should not be nil", err.Error())
+	})
+
+	t.Run("Get latest", func(t *testing.T) {
+		got, err := repo.GetLatest()
+		assert.Nil(t, err)
+		assert.Equal(t, latest.ID, got.ID)
+	})
+
+	t.Run("Returned entity is a copy", func(t *testing.T) {
+		got, _ := repo.GetById(first.ID)
+		got.Age = 100
+
+		again, _ := repo.GetById(first.ID)
+		assert.Equal(t, 1, again.Age)
+	})
+}
+
+func TestMemoryRepository_Search(t *testing.T) {
+	repo, err := NewMemory[testMemory](WithDefaultSort("age DESC"))
+	assert.Nil(t, err)
+
+	for n := 1; n <= 25; n++ {
+		createMemory(t, repo, "search", n)
+	}
+
+	createMemory(t, repo, "other", 100)
+
+	t.Run("First page", func(t *testing.T) {
+		repo.PageSize = 10
+
+		resp, err := repo.Search(1, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 10)
+		assert.Equal(t, 25, resp.Response.Entities[0].Age)
+		assert.Equal(t, int64(25), resp.Response.TotalCount)
+		assert.Equal(t, int64(3), resp.Response.TotalPages)
+		assert.True(t, resp.Response.HasNextPage)
+		assert.False(t, resp.Response.HasPrevPage)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		repo.PageSize = 10
+
+		resp, err := repo.Search(3, "group = ? AND age <= ?", "search", 25)
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 5)
+		assert.False(t, resp.Response.HasNextPage)
+		assert.True(t, resp.Response.HasPrevPage)
+	})
+
+	t.Run("Unpaged", func(t *testing.T) {
+		repo.PageSize = 0
+
+		resp, err := repo.Search(1, map[string]interface{}{"group": "search"})
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 25)
+		assert.Equal(t, int64(1), resp.Response.TotalPages)
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		repo.PageSize = 10
+
+		slice, err := repo.SearchSlice(2, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 10)
+		assert.Equal(t, 15, slice.Entities[0].Age)
+		assert.True(t, slice.HasNextPage)
+
+		slice, err = repo.SearchSlice(3, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 5)
+		assert.False(t, slice.HasNextPage)
+	})
+
+	t.Run("Search unpaged", func(t *testing.T) {
+		repo.PageSize = 10
+
+		resp, err := repo.SearchUnpaged("group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 25)
+		assert.Equal(t, int64(25), resp.Response.TotalCount)
+		assert.False(t, resp.Response.HasNextPage)
+	})
+
+	t.Run("Search options", func(t *testing.T) {
+		repo.PageSize = 10
+
+		resp, err := repo.Search(1, "group = ?", "search", SearchPageSize(4), SearchSort("age ASC"))
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 4)
+		assert.Equal(t, 1, resp.Response.Entities[0].Age)
+		assert.Equal(t, int64(7), resp.Response.TotalPages)
+
+		resp, err = repo.SearchWith(1, []SearchOption{SearchPageSize(4), SearchSort("age DESC")}, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 4)
+		assert.Equal(t, int64(7), resp.Response.TotalPages)
+		assert.Greater(t, resp.Response.Entities[0].Age, resp.Response.Entities[3].Age)
+
+		desc := []SearchOption{SearchPageSize(4), SearchSort("age DESC")}
+
+		slice, err := repo.SearchSliceWith(1, desc, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 4)
+		assert.Equal(t, resp.Response.Entities[0].Age, slice.Entities[0].Age)
+
+		entities, err := repo.SearchAllWith(desc, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Equal(t, int(resp.Response.TotalCount), len(entities))
+		assert.Equal(t, resp.Response.Entities[0].Age, entities[0].Age)
+
+		unpaged, err := repo.SearchUnpagedWith(desc, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Equal(t, entities, unpaged.Response.Entities)
+
+		count, err := repo.CountWith(desc, "group = ?", "search")
+		assert.Nil(t, err)
+		assert.Equal(t, resp.Response.TotalCount, count)
+
+		_, err = repo.SearchAll("group = ?", "search", SearchSort("unknown"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Search all with operators", func(t *testing.T) {
+		entities, err := repo.SearchAll("age IN (?) AND name IS NOT NULL AND email LIKE ?", []int{3, 5, 100}, "%@MAIL.COM")
+		assert.Nil(t, err)
+		assert.Len(t, entities, 3)
+		assert.Equal(t, 100, entities[0].Age)
+	})
+
+	t.Run("Unsupported criteria", func(t *testing.T) {
+		entities, err := repo.SearchAll("age = ? OR age = ?", 1, 2)
+		assert.NotNil(t, err)
+		assert.Empty(t, entities)
+
+		_, err = repo.Search(1, "this_field_generate_error = ?", 1)
+		assert.Equal(t, `unknown column: "this_field_generate_error"`, err.Error())
+	})
+}
+
+func TestMemoryRepository_Recency(t *testing.T) {
+	repo, err := NewMemory[testMemory](WithLatestColumn("age"))
+	assert.Nil(t, err)
+
+	createMemory(t, repo, "a", 30)
+	youngest := createMemory(t, repo, "a", 10)
+	oldest := createMemory(t, repo, "a", 50)
+
+	latest, err := repo.GetLatest()
+	assert.Nil(t, err)
+	assert.Equal(t, oldest.ID, latest.ID)
+
+	first, err := repo.GetOldest()
+	assert.Nil(t, err)
+	assert.Equal(t, youngest.ID, first.ID)
+
+	entities, err := repo.GetLatestN(2, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{50, 30}, []int{entities[0].Age, entities[1].Age})
+}
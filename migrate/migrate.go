@@ -0,0 +1,401 @@
+// Package migrate manages versioned schema migrations for the databases used by gormet repositories.
+//
+// Migrations are Go functions identified by a unique name and applied in the order they are
+// registered. The applied migrations are recorded in a tracking table (schema_migrations by default),
+// so each migration runs once. Every migration runs in its own transaction together with its record,
+// and a lock table, refreshed while the migrations run, prevents concurrent runners, such as several
+// replicas starting at once, from applying the same migrations.
+//
+// Usage:
+//
+//	migrator, err := migrate.New(db, []migrate.Migration{
+//		{
+//			ID:   "20240101_create_users",
+//			Up:   func(tx *gorm.DB) error { return tx.Migrator().CreateTable(&User{}) },
+//			Down: func(tx *gorm.DB) error { return tx.Migrator().DropTable(&User{}) },
+//		},
+//	}, migrate.Options{})
+//	if err != nil {
+//		// Handle error
+//	}
+//
+//	if err := migrator.Migrate(ctx); err != nil {
+//		// Handle error
+//	}
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"gorm.io/gorm"
+)
+
+// Migration is a named change of the database schema.
+type Migration struct {
+	ID   string                  // Unique name of the migration, such as "20240101_create_users".
+	Up   func(tx *gorm.DB) error // Applies the migration.
+	Down func(tx *gorm.DB) error // Reverts the migration, nil if it is irreversible.
+}
+
+// Options configures a Migrator.
+type Options struct {
+	TableName   string        // Name of the tracking table, defaults to "schema_migrations".
+	LockTimeout time.Duration // How long to wait for the lock held by another runner, defaults to 1 minute.
+	LockTTL     time.Duration // Delay without refresh after which a lock is considered abandoned and taken over, defaults to 10 minutes.
+	Owner       string        // Identifies the runner holding the lock, defaults to the host name and process id.
+}
+
+// Status describes the state of a migration.
+type Status struct {
+	ID         string     // Name of the migration.
+	Applied    bool       // Whether the migration was applied.
+	AppliedAt  *time.Time // When the migration was applied, nil if it is pending.
+	Registered bool       // Whether the migration is known by the Migrator, false for applied migrations removed from the code.
+}
+
+// record is a row of the tracking table.
+type record struct {
+	ID        string    `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// lock is the single row of the lock table, present while a runner holds the lock.
+type lock struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"not null"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+// lockPollInterval is the delay between attempts to acquire the lock.
+const lockPollInterval = 100 * time.Millisecond
+
+// Migrator applies and reverts a list of migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	options    Options
+}
+
+// New creates a Migrator for the given migrations, which are applied in the order of the list.
+//
+// Returns:
+// - A pointer to the Migrator if successful.
+// - An error if the database is nil, a migration has no ID or Up function, or two migrations have the same ID.
+func New(db *gorm.DB, migrations []Migration, options Options) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("the database should not be nil")
+	}
+
+	seen := make(map[string]bool, len(migrations))
+	for i, migration := range migrations {
+		if migration.ID == "" {
+			return nil, fmt.Errorf("the migration at position %d has no id", i)
+		}
+
+		if migration.Up == nil {
+			return nil, fmt.Errorf("the migration %s has no up function", migration.ID)
+		}
+
+		if seen[migration.ID] {
+			return nil, fmt.Errorf("duplicated migration id: %s", migration.ID)
+		}
+
+		seen[migration.ID] = true
+	}
+
+	if options.TableName == "" {
+		options.TableName = "schema_migrations"
+	}
+
+	if options.LockTimeout <= 0 {
+		options.LockTimeout = time.Minute
+	}
+
+	if options.LockTTL <= 0 {
+		options.LockTTL = 10 * time.Minute
+	}
+
+	if options.Owner == "" {
+		host, _ := os.Hostname()
+		options.Owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: append([]Migration(nil), migrations...),
+		options:    options,
+	}, nil
+}
+
+// Migrate applies the pending migrations in order. It stops at the first failing migration,
+// whose changes are rolled back, keeping the previous migrations applied.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.ID]; ok {
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+
+				return tx.Table(m.options.TableName).Create(&record{ID: migration.ID, AppliedAt: time.Now()}).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("migration %s failed: %v", migration.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Rollback reverts the given number of applied migrations, starting from the last one.
+// It fails without reverting anything if one of them is irreversible or not registered.
+func (m *Migrator) Rollback(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return errors.New("the number of steps should be at least 1")
+	}
+
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		var records []record
+		if err := db.Table(m.options.TableName).Find(&records).Error; err != nil {
+			return err
+		}
+
+		index := make(map[string]int, len(m.migrations))
+		for i, migration := range m.migrations {
+			index[migration.ID] = i
+		}
+
+		// The last applied migration comes first, using the registration order for ties.
+		sort.Slice(records, func(i, j int) bool {
+			if !records[i].AppliedAt.Equal(records[j].AppliedAt) {
+				return records[i].AppliedAt.After(records[j].AppliedAt)
+			}
+
+			return index[records[i].ID] > index[records[j].ID]
+		})
+
+		var targets []Migration
+		for _, rec := range records[:min(steps, len(records))] {
+			i, ok := index[rec.ID]
+			if !ok {
+				return fmt.Errorf("the applied migration %s is not registered", rec.ID)
+			}
+
+			targets = append(targets, m.migrations[i])
+		}
+
+		for _, migration := range targets {
+			if migration.Down == nil {
+				return fmt.Errorf("the migration %s is irreversible", migration.ID)
+			}
+		}
+
+		for _, migration := range targets {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+
+				return tx.Table(m.options.TableName).Where("id = ?", migration.ID).Delete(&record{}).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("rollback of migration %s failed: %v", migration.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of the registered migrations, in order, followed by the applied
+// migrations that are no longer registered.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+
+	if err := m.setup(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{ID: migration.ID, Registered: true}
+
+		if appliedAt, ok := applied[migration.ID]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.ID)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	var records []record
+	if err := db.Table(m.options.TableName).Order("applied_at, id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	for _, rec := range records {
+		if _, ok := applied[rec.ID]; ok {
+			appliedAt := rec.AppliedAt
+			statuses = append(statuses, Status{ID: rec.ID, Applied: true, AppliedAt: &appliedAt})
+		}
+	}
+
+	return statuses, nil
+}
+
+// setup creates the tracking and lock tables if they don't exist.
+func (m *Migrator) setup(db *gorm.DB) error {
+	if err := db.Table(m.options.TableName).AutoMigrate(&record{}); err != nil {
+		return fmt.Errorf("impossible to create the migrations table: %v", err)
+	}
+
+	if err := db.Table(m.lockTable()).AutoMigrate(&lock{}); err != nil {
+		return fmt.Errorf("impossible to create the migrations lock table: %v", err)
+	}
+
+	return nil
+}
+
+// applied returns the applied migrations with the time they were applied.
+func (m *Migrator) applied(db *gorm.DB) (map[string]time.Time, error) {
+	var records []record
+	if err := db.Table(m.options.TableName).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time, len(records))
+	for _, rec := range records {
+		applied[rec.ID] = rec.AppliedAt
+	}
+
+	return applied, nil
+}
+
+// lockTable returns the name of the lock table.
+func (m *Migrator) lockTable() string {
+	return m.options.TableName + "_lock"
+}
+
+// withLock runs the function while holding the migrations lock. The lock is refreshed in the
+// background, so it is not taken over by another runner while a long migration runs.
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+
+	if err := m.setup(db); err != nil {
+		return err
+	}
+
+	if err := m.acquire(ctx, db); err != nil {
+		return err
+	}
+
+	defer db.Table(m.lockTable()).Where("id = ? AND owner = ?", 1, m.options.Owner).Delete(&lock{})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lost := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		m.refresh(runCtx, db, lost, cancel)
+	}()
+
+	err := fn(m.db.WithContext(runCtx))
+
+	cancel()
+	<-stopped
+
+	select {
+	case <-lost:
+		if err == nil || errors.Is(err, context.Canceled) {
+			return errors.New("the migrations lock was taken over by another runner")
+		}
+	default:
+	}
+
+	return err
+}
+
+// refresh updates the time of the lock at a third of LockTTL until the context is canceled, closing
+// lost and canceling the context if the lock was taken over.
+func (m *Migrator) refresh(ctx context.Context, db *gorm.DB, lost chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(m.options.LockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result := db.Table(m.lockTable()).Where("id = ? AND owner = ?", 1, m.options.Owner).Update("locked_at", time.Now())
+
+			// Transient errors are retried on the next tick, the lock being valid for two more.
+			if result.Error == nil && result.RowsAffected == 0 {
+				close(lost)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// acquire inserts the lock row, waiting for the runner holding it to release it. A lock not refreshed
+// for LockTTL is considered abandoned and taken over.
+func (m *Migrator) acquire(ctx context.Context, db *gorm.DB) error {
+	deadline := time.Now().Add(m.options.LockTimeout)
+
+	for {
+		err := db.Table(m.lockTable()).Create(&lock{ID: 1, Owner: m.options.Owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+
+		// Only a lock held by another runner is waited for.
+		if !gormet.IsDuplicateKey(err) {
+			return fmt.Errorf("impossible to acquire the migrations lock: %v", err)
+		}
+
+		// Remove an abandoned lock, so the next attempt can take it over.
+		expired := time.Now().Add(-m.options.LockTTL)
+		db.Table(m.lockTable()).Where("id = ? AND locked_at < ?", 1, expired).Delete(&lock{})
+
+		if time.Now().After(deadline) {
+			var holder lock
+			db.Table(m.lockTable()).Where("id = ?", 1).Take(&holder)
+
+			return fmt.Errorf("timeout waiting for the migrations lock held by %q", holder.Owner)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
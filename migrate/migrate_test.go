@@ -0,0 +1,245 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func getGormConnection(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("./database.db"), &gorm.Config{})
+	assert.Nil(t, err)
+
+	return db
+}
+
+// createTable returns a migration creating a table with a single column.
+func createTable(id string, table string) Migration {
+	return Migration{
+		ID:   id,
+		Up:   func(tx *gorm.DB) error { return tx.Exec("CREATE TABLE " + table + " (id integer PRIMARY KEY)").Error },
+		Down: func(tx *gorm.DB) error { return tx.Exec("DROP TABLE " + table).Error },
+	}
+}
+
+// uniqueOptions returns options with a dedicated tracking table, so tests don't share state.
+func uniqueOptions() Options {
+	return Options{TableName: "migrations_" + uuid.NewString()[:8]}
+}
+
+func TestNew(t *testing.T) {
+	db := getGormConnection(t)
+	up := func(tx *gorm.DB) error { return nil }
+
+	t.Run("Valid migrations", func(t *testing.T) {
+		m, err := New(db, []Migration{{ID: "1", Up: up}, {ID: "2", Up: up}}, Options{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "schema_migrations", m.options.TableName)
+		assert.NotEmpty(t, m.options.Owner)
+	})
+
+	t.Run("Invalid migrations", func(t *testing.T) {
+		_, err := New(db, []Migration{{ID: "1", Up: up}, {ID: "1", Up: up}}, Options{})
+		assert.Equal(t, "duplicated migration id: 1", err.Error())
+
+		_, err = New(db, []Migration{{Up: up}}, Options{})
+		assert.Equal(t, "the migration at position 0 has no id", err.Error())
+
+		_, err = New(db, []Migration{{ID: "1"}}, Options{})
+		assert.Equal(t, "the migration 1 has no up function", err.Error())
+
+		_, err = New(nil, nil, Options{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	db := getGormConnection(t)
+	ctx := context.Background()
+
+	suffix := uuid.NewString()[:8]
+	first, second := "first_"+suffix, "second_"+suffix
+
+	migrations := []Migration{
+		createTable("001_first", first),
+		createTable("002_second", second),
+	}
+
+	m, err := New(db, migrations, uniqueOptions())
+	assert.Nil(t, err)
+
+	t.Run("Pending status", func(t *testing.T) {
+		statuses, err := m.Status(ctx)
+
+		assert.Nil(t, err)
+		assert.Len(t, statuses, 2)
+		assert.False(t, statuses[0].Applied)
+		assert.True(t, statuses[0].Registered)
+	})
+
+	t.Run("Migrate", func(t *testing.T) {
+		assert.Nil(t, m.Migrate(ctx))
+		assert.True(t, db.Migrator().HasTable(first))
+		assert.True(t, db.Migrator().HasTable(second))
+
+		// Running again is a no-op.
+		assert.Nil(t, m.Migrate(ctx))
+
+		statuses, err := m.Status(ctx)
+		assert.Nil(t, err)
+		assert.True(t, statuses[0].Applied)
+		assert.True(t, statuses[1].Applied)
+		assert.NotNil(t, statuses[1].AppliedAt)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		assert.Nil(t, m.Rollback(ctx, 1))
+		assert.True(t, db.Migrator().HasTable(first))
+		assert.False(t, db.Migrator().HasTable(second))
+
+		statuses, err := m.Status(ctx)
+		assert.Nil(t, err)
+		assert.True(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+
+		assert.Nil(t, m.Rollback(ctx, 5))
+		assert.False(t, db.Migrator().HasTable(first))
+
+		assert.NotNil(t, m.Rollback(ctx, 0))
+	})
+
+	t.Run("Unregistered migration", func(t *testing.T) {
+		assert.Nil(t, m.Migrate(ctx))
+
+		other, err := New(db, migrations[:1], m.options)
+		assert.Nil(t, err)
+
+		statuses, err := other.Status(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, statuses, 2)
+		assert.False(t, statuses[1].Registered)
+
+		err = other.Rollback(ctx, 1)
+		assert.Equal(t, "the applied migration 002_second is not registered", err.Error())
+	})
+}
+
+func TestMigrator_Failures(t *testing.T) {
+	db := getGormConnection(t)
+	ctx := context.Background()
+
+	t.Run("Failing migration is rolled back", func(t *testing.T) {
+		table := "failing_" + uuid.NewString()[:8]
+
+		m, err := New(db, []Migration{{
+			ID: "001_failing",
+			Up: func(tx *gorm.DB) error {
+				if err := tx.Exec("CREATE TABLE " + table + " (id integer)").Error; err != nil {
+					return err
+				}
+
+				return errors.New("boom")
+			},
+		}}, uniqueOptions())
+		assert.Nil(t, err)
+
+		err = m.Migrate(ctx)
+		assert.Equal(t, "migration 001_failing failed: boom", err.Error())
+		assert.False(t, db.Migrator().HasTable(table))
+
+		statuses, err := m.Status(ctx)
+		assert.Nil(t, err)
+		assert.False(t, statuses[0].Applied)
+	})
+
+	t.Run("Irreversible migration", func(t *testing.T) {
+		m, err := New(db, []Migration{{ID: "001", Up: func(tx *gorm.DB) error { return nil }}}, uniqueOptions())
+		assert.Nil(t, err)
+
+		assert.Nil(t, m.Migrate(ctx))
+		assert.Equal(t, "the migration 001 is irreversible", m.Rollback(ctx, 1).Error())
+	})
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	db := getGormConnection(t)
+	ctx := context.Background()
+
+	options := uniqueOptions()
+	options.LockTimeout = 150 * time.Millisecond
+
+	m, err := New(db, []Migration{{ID: "001", Up: func(tx *gorm.DB) error { return nil }}}, options)
+	assert.Nil(t, err)
+	assert.Nil(t, m.setup(db))
+
+	t.Run("Lock held by another runner", func(t *testing.T) {
+		assert.Nil(t, db.Table(m.lockTable()).Create(&lock{ID: 1, Owner: "other", LockedAt: time.Now()}).Error)
+
+		err := m.Migrate(ctx)
+		assert.Equal(t, `timeout waiting for the migrations lock held by "other"`, err.Error())
+	})
+
+	t.Run("Abandoned lock is taken over", func(t *testing.T) {
+		err := db.Table(m.lockTable()).Where("id = ?", 1).Update("locked_at", time.Now().Add(-time.Hour)).Error
+		assert.Nil(t, err)
+
+		assert.Nil(t, m.Migrate(ctx))
+
+		var count int64
+		db.Table(m.lockTable()).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Lock refreshed during a long migration", func(t *testing.T) {
+		options := uniqueOptions()
+		options.LockTTL = 60 * time.Millisecond
+		options.Owner = "first"
+
+		started := make(chan struct{})
+		slow, err := New(db, []Migration{{ID: "001", Up: func(tx *gorm.DB) error {
+			close(started)
+			time.Sleep(300 * time.Millisecond)
+			return nil
+		}}}, options)
+		assert.Nil(t, err)
+
+		options.Owner = "second"
+		options.LockTimeout = 200 * time.Millisecond
+		other, err := New(db, []Migration{{ID: "001", Up: func(tx *gorm.DB) error { return nil }}}, options)
+		assert.Nil(t, err)
+
+		done := make(chan error)
+		go func() { done <- slow.Migrate(ctx) }()
+
+		<-started
+		err = other.Migrate(ctx)
+		assert.Equal(t, `timeout waiting for the migrations lock held by "first"`, err.Error())
+		assert.Nil(t, <-done)
+	})
+
+	t.Run("Lock error not caused by another runner", func(t *testing.T) {
+		failing := getGormConnection(t)
+		err := failing.Callback().Create().Before("gorm:create").Register("test:deny", func(tx *gorm.DB) {
+			tx.AddError(errors.New("permission denied"))
+		})
+		assert.Nil(t, err)
+
+		options := uniqueOptions()
+		options.LockTimeout = time.Minute
+
+		m, err := New(failing, []Migration{{ID: "001", Up: func(tx *gorm.DB) error { return nil }}}, options)
+		assert.Nil(t, err)
+
+		start := time.Now()
+		err = m.Migrate(ctx)
+		assert.Equal(t, "impossible to acquire the migrations lock: permission denied", err.Error())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,484 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// SoftDeleteStrategy defines how the repository removes entities from the database.
+type SoftDeleteStrategy int
+
+const (
+	// SoftDeleteAuto lets GORM decide: entities with a gorm.DeletedAt field are
+	// logically deleted, all the others are physically deleted.
+	SoftDeleteAuto SoftDeleteStrategy = iota
+
+	// SoftDeleteAlways requires the entity to have a gorm.DeletedAt field and always
+	// performs a logical delete. New fails if the field is missing.
+	SoftDeleteAlways
+
+	// SoftDeleteNever always physically deletes the entity, even if it has a gorm.DeletedAt field.
+	SoftDeleteNever
+)
+
+// Option configures a Repository at construction time. Options are applied in the order
+// they are passed to New and validated against the model schema before the repository is returned.
+type Option func(*config) error
+
+// config holds the settings assembled from the options passed to New.
+type config struct {
+	pageSize    uint               // Default page size used by paginated searches.
+	maxPageSize uint               // Upper bound for the page size, 0 means unlimited.
+	defaultSort string             // Order clause applied to searches.
+	softDelete  SoftDeleteStrategy // How entities are removed from the database.
+	logger      logger.Interface   // Logger used by the repository session.
+	cache       Cache              // Cache used by GetById.
+	cacheTTL    time.Duration      // Time to live of cached entities.
+	hooks       []Hooks            // Hooks invoked around write operations.
+	retry       *retryState        // Retry policy and counters, nil when retries are disabled.
+	schemaCheck bool               // Whether New verifies the table of the model.
+	latest      string             // Column defining the recency of the entities.
+	history     bool               // Whether the versions of the entities are recorded in a history table.
+	temporal    *temporal          // History table of the model, set by New when history is enabled.
+	treeParent  string             // Column referencing the parent entity, empty when the tree mode is disabled.
+
+	defaultScopes []scope          // Criteria applied to every read, unless the repository is unscoped.
+	namedScopes   map[string]scope // Criteria applied to the searches passing their name to Scope.
+
+	concurrentCount bool          // Whether Search runs the page and count queries in parallel.
+	countCache      Cache         // Cache of the total counts computed by Search.
+	countCacheTTL   time.Duration // Time to live of cached total counts.
+}
+
+// defaultConfig returns the configuration used when no option is provided.
+func defaultConfig() *config {
+	return &config{
+		softDelete: SoftDeleteAuto,
+	}
+}
+
+// WithPageSize sets the default number of entities returned per page by Search.
+// A page size of 0 disables the pagination.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithPageSize(20))
+func WithPageSize(size uint) Option {
+	return func(c *config) error {
+		c.pageSize = size
+		return nil
+	}
+}
+
+// WithMaxPageSize sets the upper bound for the page size of Search and SearchSlice. Page sizes greater
+// than the maximum, or a page size of 0 disabling their pagination, are clamped to it. SearchUnpaged
+// and SearchAll are not bounded: they retrieve all the matching entities.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithPageSize(20), gormet.WithMaxPageSize(100))
+func WithMaxPageSize(size uint) Option {
+	return func(c *config) error {
+		if size == 0 {
+			return errors.New("the max page size should be greater than zero")
+		}
+
+		c.maxPageSize = size
+		return nil
+	}
+}
+
+// WithDefaultSort sets the order applied to Search and SearchAll, such as "created_at DESC".
+// Each column is validated against the model schema when the repository is created.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithDefaultSort("name ASC, id DESC"))
+func WithDefaultSort(sort string) Option {
+	return func(c *config) error {
+		if strings.TrimSpace(sort) == "" {
+			return errors.New("the default sort should not be empty")
+		}
+
+		c.defaultSort = sort
+		return nil
+	}
+}
+
+// WithSoftDelete sets the strategy used by Delete and DeleteById.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithSoftDelete(gormet.SoftDeleteNever))
+func WithSoftDelete(strategy SoftDeleteStrategy) Option {
+	return func(c *config) error {
+		if strategy < SoftDeleteAuto || strategy > SoftDeleteNever {
+			return fmt.Errorf("unknown soft delete strategy: %d", strategy)
+		}
+
+		c.softDelete = strategy
+		return nil
+	}
+}
+
+// WithLogger sets the GORM logger used by every statement issued by the repository.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithLogger(logger.Default.LogMode(logger.Info)))
+func WithLogger(l logger.Interface) Option {
+	return func(c *config) error {
+		if l == nil {
+			return errors.New("the logger should not be nil")
+		}
+
+		c.logger = l
+		return nil
+	}
+}
+
+// WithCache enables the caching of entities retrieved by GetById. Cached entries expire
+// after ttl (0 means no expiration) and are invalidated by Update, Delete and DeleteById.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithCache(gormet.NewMemoryCache(), time.Minute))
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(c *config) error {
+		if cache == nil {
+			return errors.New("the cache should not be nil")
+		}
+
+		if ttl < 0 {
+			return errors.New("the cache ttl should not be negative")
+		}
+
+		c.cache = cache
+		c.cacheTTL = ttl
+		return nil
+	}
+}
+
+// WithHooks registers functions invoked around Create, Update, Delete and DeleteById.
+// The option can be passed more than once; hooks run in registration order.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithHooks(gormet.Hooks{AfterCreate: notify}))
+func WithHooks(hooks Hooks) Option {
+	return func(c *config) error {
+		if hooks.empty() {
+			return errors.New("the hooks should define at least one function")
+		}
+
+		c.hooks = append(c.hooks, hooks)
+		return nil
+	}
+}
+
+// WithLatestColumn sets the column defining the recency of the entities, used by GetLatest,
+// GetOldest and GetLatestN. Without this option, the field tagged with `gormet:"latest"` is used,
+// then the CreatedAt field when the model has one, such as gorm.Model, and finally the primary key.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithLatestColumn("published_at"))
+func WithLatestColumn(column string) Option {
+	return func(c *config) error {
+		if strings.TrimSpace(column) == "" {
+			return errors.New("the latest column should not be empty")
+		}
+
+		c.latest = column
+		return nil
+	}
+}
+
+// WithConcurrentCount makes Search run the page query and the count query in parallel, on separate
+// connections of the pool, instead of one after the other. When one of the queries fails, the other
+// is canceled. Searches running inside a transaction are always serial, as they share a single connection.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithConcurrentCount())
+func WithConcurrentCount() Option {
+	return func(c *config) error {
+		c.concurrentCount = true
+		return nil
+	}
+}
+
+// WithCountCache enables the caching of the total counts computed by Search, per criteria, so paging
+// through the same results does not repeat the count query. Cached counts are not invalidated by writes,
+// so the ttl should be short: the total count of a search may be stale for up to ttl.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithCountCache(gormet.NewMemoryCache(), 10*time.Second))
+func WithCountCache(cache Cache, ttl time.Duration) Option {
+	return func(c *config) error {
+		if cache == nil {
+			return errors.New("the count cache should not be nil")
+		}
+
+		if ttl <= 0 {
+			return errors.New("the count cache ttl should be greater than zero")
+		}
+
+		c.countCache = cache
+		c.countCacheTTL = ttl
+		return nil
+	}
+}
+
+// WithHistory enables the temporal mode: every version of an entity written by Create, Update, Delete
+// and DeleteById is copied into the history table, named after the table of the model with the _history
+// suffix, with the time range in which it was valid. The copy runs in the transaction of the write.
+// Bulk writes and counters are not recorded, as they don't invoke the hooks. The history table is
+// created by MigrateHistory, and read by GetByIdAsOf, History and SearchAsOf.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithHistory())
+func WithHistory() Option {
+	return func(c *config) error {
+		c.history = true
+		return nil
+	}
+}
+
+// WithTree enables the tree mode for self-referencing models, where the given column holds the primary
+// key of the parent entity, null or zero for the roots. The column must have the type of the primary key.
+// The hierarchy is then queried by Children, Roots, Descendants, Ancestors, Tree and Forest, and changed by Move.
+//
+// Usage:
+// repo, err := gormet.New[Category](db, gormet.WithTree("parent_id"))
+func WithTree(parentColumn string) Option {
+	return func(c *config) error {
+		if strings.TrimSpace(parentColumn) == "" {
+			return errors.New("the parent column should not be empty")
+		}
+
+		c.treeParent = parentColumn
+		return nil
+	}
+}
+
+// WithDefaultScope adds criteria applied to every read of the repository: Get, GetById, GetLatest,
+// GetOldest, GetLatestN, the Search functions, Count, Export and the tree queries, where an entity
+// hidden by the scopes also hides its descendants. The option can be passed more than once; the
+// scopes are combined with AND. Writes are not scoped, nor is the cycle check of Move, and Unscoped returns a copy of the repository
+// ignoring the default scopes.
+//
+// Usage:
+// repo, err := gormet.New[Post](db, gormet.WithDefaultScope("archived_at IS NULL"))
+func WithDefaultScope(query interface{}, args ...interface{}) Option {
+	return func(c *config) error {
+		if isBlankScope(query) {
+			return errors.New("the default scope should not be empty")
+		}
+
+		c.defaultScopes = append(c.defaultScopes, scope{query: query, args: args})
+		return nil
+	}
+}
+
+// WithNamedScope registers criteria under a name, applied to the searches passing the Scope search
+// option with this name.
+//
+// Usage:
+// repo, err := gormet.New[Post](db, gormet.WithNamedScope("published", "status = ?", "published"))
+func WithNamedScope(name string, query interface{}, args ...interface{}) Option {
+	return func(c *config) error {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("the scope name should not be empty")
+		}
+
+		if isBlankScope(query) {
+			return fmt.Errorf("the scope %q should not be empty", name)
+		}
+
+		if _, ok := c.namedScopes[name]; ok {
+			return fmt.Errorf("the scope %q is already registered", name)
+		}
+
+		if c.namedScopes == nil {
+			c.namedScopes = make(map[string]scope)
+		}
+
+		c.namedScopes[name] = scope{query: query, args: args}
+		return nil
+	}
+}
+
+// WithSchemaCheck makes New verify that the table of the model exists and has a column for
+// each field of the struct, so a missing migration is detected at startup instead of at the first query.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithSchemaCheck())
+func WithSchemaCheck() Option {
+	return func(c *config) error {
+		c.schemaCheck = true
+		return nil
+	}
+}
+
+// newConfig applies the options over the default configuration and validates the result against the model schema.
+func newConfig(sch *schema.Schema, opts []Option) (*config, error) {
+	cfg := defaultConfig()
+
+	for _, opt := range opts {
+		if opt == nil {
+			return nil, errors.New("invalid option: the option should not be nil")
+		}
+
+		if err := opt(cfg); err != nil {
+			return nil, fmt.Errorf("invalid option: %v", err)
+		}
+	}
+
+	if err := cfg.validate(sch); err != nil {
+		return nil, fmt.Errorf("invalid option: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// validate checks the consistency of the configuration against the model schema.
+func (c *config) validate(sch *schema.Schema) error {
+	if c.maxPageSize > 0 && c.pageSize > c.maxPageSize {
+		return fmt.Errorf("the page size %d exceeds the max page size %d", c.pageSize, c.maxPageSize)
+	}
+
+	if c.defaultSort != "" {
+		if err := validateSort(sch, c.defaultSort); err != nil {
+			return err
+		}
+	}
+
+	if c.latest != "" && lookUpDBField(sch, c.latest) == nil {
+		return fmt.Errorf("unknown latest column: %q", c.latest)
+	}
+
+	if c.treeParent != "" {
+		if err := validateTree(sch, c.treeParent); err != nil {
+			return err
+		}
+	}
+
+	if c.softDelete == SoftDeleteAlways && !hasSoftDeleteField(sch) {
+		return fmt.Errorf("soft delete requires a gorm.DeletedAt field in %s", sch.Name)
+	}
+
+	return nil
+}
+
+// validateSort checks that every column of an order clause exists in the model schema.
+func validateSort(sch *schema.Schema, sort string) error {
+	for _, part := range strings.Split(sort, ",") {
+		tokens := strings.Fields(part)
+
+		if len(tokens) == 0 || len(tokens) > 2 {
+			return fmt.Errorf("invalid sort expression: %q", strings.TrimSpace(part))
+		}
+
+		if len(tokens) == 2 {
+			if direction := strings.ToUpper(tokens[1]); direction != "ASC" && direction != "DESC" {
+				return fmt.Errorf("invalid sort direction: %q", tokens[1])
+			}
+		}
+
+		if sch.LookUpField(tokens[0]) == nil {
+			return fmt.Errorf("unknown sort column: %q", tokens[0])
+		}
+	}
+
+	return nil
+}
+
+// hasSoftDeleteField reports whether the model has a gorm.DeletedAt field.
+func hasSoftDeleteField(sch *schema.Schema) bool {
+	return softDeleteField(sch) != nil
+}
+
+// softDeleteField returns the gorm.DeletedAt field of the model, or nil if there is none.
+func softDeleteField(sch *schema.Schema) *schema.Field {
+	deletedAtType := reflect.TypeOf(gorm.DeletedAt{})
+
+	for _, field := range sch.Fields {
+		if field.FieldType == deletedAtType {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// checkSchema verifies that the table of the model exists and has a column for each field of the schema.
+func checkSchema(db *gorm.DB, sch *schema.Schema, model interface{}) error {
+	migrator := db.Migrator()
+
+	if !migrator.HasTable(model) {
+		return fmt.Errorf("the table %s does not exist", sch.Table)
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return fmt.Errorf("impossible to read the columns of %s: %v", sch.Table, err)
+	}
+
+	columns := make(map[string]bool, len(columnTypes))
+	for _, columnType := range columnTypes {
+		columns[strings.ToLower(columnType.Name())] = true
+	}
+
+	var missing []string
+	for _, field := range sch.Fields {
+		if field.DBName != "" && !field.IgnoreMigration && !columns[strings.ToLower(field.DBName)] {
+			missing = append(missing, field.DBName)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the table %s has no column for: %s", sch.Table, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// lookUpDBField returns the field of the schema mapped to the given column or field name, or nil.
+func lookUpDBField(sch *schema.Schema, name string) *schema.Field {
+	if field := sch.LookUpField(name); field != nil && field.DBName != "" {
+		return field
+	}
+
+	return nil
+}
+
+// hasTag reports whether the field has the given setting in its gormet tag, such as `gormet:"latest"`.
+// Settings are separated by semicolons, as in the gorm tag.
+func hasTag(field *schema.Field, setting string) bool {
+	for _, value := range strings.Split(field.Tag.Get("gormet"), ";") {
+		if strings.EqualFold(strings.TrimSpace(value), setting) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// latestColumn resolves the column defining the recency of the entities: the configured column,
+// the field tagged with `gormet:"latest"`, the CreatedAt field or the primary key, in this order.
+func latestColumn(sch *schema.Schema, configured string, pkName string) string {
+	if configured != "" {
+		return lookUpDBField(sch, configured).DBName
+	}
+
+	for _, field := range sch.Fields {
+		if field.DBName != "" && hasTag(field, "latest") {
+			return field.DBName
+		}
+	}
+
+	if field := sch.LookUpField("CreatedAt"); field != nil && field.DBName != "" && field.DataType == schema.Time {
+		return field.DBName
+	}
+
+	return pkName
+}
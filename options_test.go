@@ -0,0 +1,180 @@
+package gormet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testOptions struct {
+	gorm.Model
+	Name string `json:"name" gorm:"unique;not null;default:null"`
+}
+
+type testOptionsNoSoftDelete struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+func TestNew_Options(t *testing.T) {
+	db := getGormConnection(t, &testOptions{})
+
+	t.Run("Default configuration", func(t *testing.T) {
+		repo, err := New[testOptions](db)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(0), repo.PageSize)
+		assert.Equal(t, SoftDeleteAuto, repo.config.softDelete)
+	})
+
+	t.Run("Page size options", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithPageSize(20), WithMaxPageSize(50))
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(20), repo.PageSize)
+		assert.Equal(t, uint(20), repo.pageSize())
+
+		repo.PageSize = 500
+		assert.Equal(t, uint(50), repo.pageSize())
+
+		repo.PageSize = 0
+		assert.Equal(t, uint(50), repo.pageSize())
+	})
+
+	t.Run("Page size greater than max", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithPageSize(200), WithMaxPageSize(50))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+		assert.Equal(t, "invalid option: the page size 200 exceeds the max page size 50", err.Error())
+	})
+
+	t.Run("Zero max page size", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithMaxPageSize(0))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Valid default sort", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithDefaultSort("name asc, id DESC"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "name asc, id DESC", repo.config.defaultSort)
+	})
+
+	t.Run("Unknown sort column", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithDefaultSort("unknown DESC"))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+		assert.Equal(t, `invalid option: unknown sort column: "unknown"`, err.Error())
+	})
+
+	t.Run("Invalid sort direction", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithDefaultSort("name UP"))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+		assert.Equal(t, `invalid option: invalid sort direction: "UP"`, err.Error())
+	})
+
+	t.Run("Soft delete always without DeletedAt", func(t *testing.T) {
+		db.AutoMigrate(&testOptionsNoSoftDelete{})
+
+		repo, err := New[testOptionsNoSoftDelete](db, WithSoftDelete(SoftDeleteAlways))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Soft delete always with DeletedAt", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithSoftDelete(SoftDeleteAlways))
+
+		assert.Nil(t, err)
+		assert.Equal(t, SoftDeleteAlways, repo.config.softDelete)
+	})
+
+	t.Run("Unknown soft delete strategy", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithSoftDelete(SoftDeleteStrategy(42)))
+
+		assert.Nil(t, repo)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Nil values", func(t *testing.T) {
+		_, err := New[testOptions](db, WithLogger(nil))
+		assert.NotNil(t, err)
+
+		_, err = New[testOptions](db, WithCache(nil, time.Minute))
+		assert.NotNil(t, err)
+
+		_, err = New[testOptions](db, WithHooks(Hooks{}))
+		assert.NotNil(t, err)
+
+		_, err = New[testOptions](db, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Logger", func(t *testing.T) {
+		l := logger.Default.LogMode(logger.Silent)
+		repo, err := New[testOptions](db, WithLogger(l))
+
+		assert.Nil(t, err)
+		assert.Equal(t, l, repo.db.Logger)
+	})
+}
+
+func TestRepository_SoftDeleteNever(t *testing.T) {
+	db := getGormConnection(t, &testOptions{})
+
+	repo, err := New[testOptions](db, WithSoftDelete(SoftDeleteNever))
+	assert.Nil(t, err)
+
+	entity := &testOptions{Name: time.Now().String()}
+	assert.Nil(t, repo.Create(entity))
+	assert.Nil(t, repo.DeleteById(entity.ID))
+
+	var count int64
+	db.Unscoped().Model(&testOptions{}).Where("id = ?", entity.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestNew_SchemaCheck(t *testing.T) {
+	db := getGormConnection(t, &testOptions{})
+
+	t.Run("Table matches the struct", func(t *testing.T) {
+		repo, err := New[testOptions](db, WithSchemaCheck())
+
+		assert.Nil(t, err)
+		assert.NotNil(t, repo)
+	})
+
+	t.Run("Missing table", func(t *testing.T) {
+		type testMissingTable struct {
+			ID uint `gorm:"primaryKey"`
+		}
+
+		repo, err := New[testMissingTable](db, WithSchemaCheck())
+
+		assert.Nil(t, repo)
+		assert.Equal(t, "schema check failed: the table test_missing_tables does not exist", err.Error())
+	})
+
+	t.Run("Missing column", func(t *testing.T) {
+		type testMissingColumn struct {
+			ID   uint `gorm:"primaryKey"`
+			Name string
+		}
+
+		assert.Nil(t, db.Exec("CREATE TABLE IF NOT EXISTS test_missing_columns (id integer PRIMARY KEY)").Error)
+
+		repo, err := New[testMissingColumn](db, WithSchemaCheck())
+
+		assert.Nil(t, repo)
+		assert.Equal(t, "schema check failed: the table test_missing_columns has no column for: name", err.Error())
+	})
+}
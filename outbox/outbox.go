@@ -0,0 +1,136 @@
+// Package outbox implements the transactional outbox pattern for the repositories of gormet.
+//
+// The events describing the changes of the entities are stored in an outbox table inside the same
+// transaction as the changes themselves, so an event is recorded if and only if the change is
+// committed. A Relay then reads the pending events and delivers them through a Publisher, marking
+// them as sent. Events are delivered at least once: consumers must tolerate duplicates.
+//
+// Usage:
+//
+//	if err := outbox.AutoMigrate(db); err != nil {
+//		// Handle error
+//	}
+//
+//	repo, err := gormet.New[User](db, gormet.WithHooks(outbox.Hooks()))
+//	if err != nil {
+//		// Handle error
+//	}
+//
+//	relay := outbox.NewRelay(db, publisher, outbox.RelayOptions{})
+//	go relay.Run(ctx)
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"gorm.io/gorm"
+)
+
+// Event types recorded by the hooks returned by Hooks.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event is a record of the outbox table describing a change of an entity.
+type Event struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Aggregate   string     `json:"aggregate" gorm:"not null;index"` // The table of the changed entity.
+	AggregateID string     `json:"aggregateId" gorm:"not null"`     // The primary key of the changed entity.
+	Type        string     `json:"type" gorm:"not null"`            // The kind of change, such as EventCreated.
+	Payload     string     `json:"payload"`                         // The JSON encoded entity.
+	CreatedAt   time.Time  `json:"createdAt"`
+	SentAt      *time.Time `json:"sentAt" gorm:"index"` // When the event was published, nil while pending.
+	Attempts    int        `json:"attempts"`            // Number of failed publication attempts.
+	LastError   string     `json:"lastError"`           // Error of the last failed publication attempt.
+}
+
+// TableName returns the name of the outbox table.
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// AutoMigrate creates or updates the outbox table.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Event{})
+}
+
+// Hooks returns the repository hooks that enqueue an event after each Create, Update and Delete,
+// inside the transaction of the write operation.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithHooks(outbox.Hooks()))
+func Hooks() gormet.Hooks {
+	return gormet.Hooks{
+		AfterCreate: hook(EventCreated),
+		AfterUpdate: hook(EventUpdated),
+		AfterDelete: hook(EventDeleted),
+	}
+}
+
+// hook returns a hook function enqueueing an event of the given type.
+func hook(eventType string) gormet.HookFunc {
+	return func(tx *gorm.DB, entity interface{}) error {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(entity); err != nil {
+			return fmt.Errorf("data struct parse error: %s", err.Error())
+		}
+
+		var id interface{}
+		if field := stmt.Schema.PrioritizedPrimaryField; field != nil {
+			id, _ = field.ValueOf(tx.Statement.Context, reflect.Indirect(reflect.ValueOf(entity)))
+		}
+
+		return Enqueue(tx, stmt.Schema.Table, fmt.Sprint(id), eventType, entity)
+	}
+}
+
+// Enqueue stores an event in the outbox table using the given transaction. It can be used to
+// record custom events together with the changes made in the same transaction.
+//
+// Usage:
+//
+//	err := db.Transaction(func(tx *gorm.DB) error {
+//		// ... change the entities
+//		return outbox.Enqueue(tx, "orders", "42", "shipped", order)
+//	})
+//
+// Parameters:
+// - tx: The transaction in which the event is stored.
+// - aggregate: The name of the changed aggregate, such as a table name.
+// - aggregateID: The identifier of the changed aggregate.
+// - eventType: The kind of change.
+// - payload: The value encoded as JSON in the event payload, may be nil.
+//
+// Returns:
+// - nil if the event is stored.
+// - An error if the payload can't be encoded or the event can't be stored.
+func Enqueue(tx *gorm.DB, aggregate string, aggregateID string, eventType string, payload interface{}) error {
+	if tx == nil {
+		return errors.New("the transaction should not be nil")
+	}
+
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("impossible to encode payload: %v", err)
+		}
+	}
+
+	event := &Event{
+		Aggregate:   aggregate,
+		AggregateID: aggregateID,
+		Type:        eventType,
+		Payload:     string(data),
+	}
+
+	// A new session keeps the transaction but discards the conditions of the current statement.
+	return tx.Session(&gorm.Session{NewDB: true}).Create(event).Error
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gsdenys/gormet"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type testOrder struct {
+	gorm.Model
+	Number string `json:"number" gorm:"unique;not null;default:null"`
+}
+
+func getGormConnection(t *testing.T, entities ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("./database.db"), &gorm.Config{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, AutoMigrate(db))
+	db.AutoMigrate(entities...)
+
+	return db
+}
+
+// eventsOf returns the events stored for the given aggregate id.
+func eventsOf(t *testing.T, db *gorm.DB, id uint) []Event {
+	var events []Event
+	err := db.Where("aggregate = ? AND aggregate_id = ?", "test_orders", fmt.Sprint(id)).Order("id").Find(&events).Error
+	assert.Nil(t, err)
+
+	return events
+}
+
+func TestHooks(t *testing.T) {
+	db := getGormConnection(t, &testOrder{})
+
+	repo, err := gormet.New[testOrder](db, gormet.WithHooks(Hooks()))
+	assert.Nil(t, err)
+
+	t.Run("Events for each write", func(t *testing.T) {
+		order := &testOrder{Number: uuid.NewString()}
+
+		assert.Nil(t, repo.Create(order))
+		assert.Nil(t, repo.Update(order))
+		assert.Nil(t, repo.DeleteById(order.ID))
+
+		events := eventsOf(t, db, order.ID)
+		assert.Len(t, events, 3)
+		assert.Equal(t, []string{EventCreated, EventUpdated, EventDeleted}, []string{events[0].Type, events[1].Type, events[2].Type})
+		assert.Nil(t, events[0].SentAt)
+
+		var payload testOrder
+		assert.Nil(t, json.Unmarshal([]byte(events[0].Payload), &payload))
+		assert.Equal(t, order.Number, payload.Number)
+	})
+
+	t.Run("No event when the write fails", func(t *testing.T) {
+		order := &testOrder{Number: uuid.NewString()}
+		assert.Nil(t, repo.Create(order))
+
+		duplicated := &testOrder{Number: order.Number}
+		assert.NotNil(t, repo.Create(duplicated))
+
+		var count int64
+		db.Model(&Event{}).Where("payload LIKE ?", "%"+order.Number+"%").Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("No event when the transaction rolls back", func(t *testing.T) {
+		order := &testOrder{Number: uuid.NewString()}
+
+		err := repo.Transaction(func(tx *gormet.Repository[testOrder]) error {
+			if err := tx.Create(order); err != nil {
+				return err
+			}
+
+			return errors.New("abort")
+		})
+		assert.NotNil(t, err)
+		assert.Empty(t, eventsOf(t, db, order.ID))
+	})
+}
+
+func TestEnqueue(t *testing.T) {
+	db := getGormConnection(t)
+
+	t.Run("Custom event", func(t *testing.T) {
+		id := uuid.NewString()
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return Enqueue(tx, "orders", id, "shipped", map[string]string{"carrier": "post"})
+		})
+		assert.Nil(t, err)
+
+		var event Event
+		assert.Nil(t, db.Where("aggregate_id = ?", id).First(&event).Error)
+		assert.Equal(t, "shipped", event.Type)
+		assert.Equal(t, `{"carrier":"post"}`, event.Payload)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		err := Enqueue(db, "orders", "1", "shipped", func() {})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Nil transaction", func(t *testing.T) {
+		err := Enqueue(nil, "orders", "1", "shipped", nil)
+		assert.Equal(t, "the transaction should not be nil", err.Error())
+	})
+}
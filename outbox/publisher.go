@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// Publisher delivers the events of the outbox to a message broker or any other destination.
+type Publisher interface {
+	// Publish delivers the event. Returning an error keeps the event pending, so it is published again later.
+	Publish(ctx context.Context, event Event) error
+}
+
+// MemoryPublisher is a Publisher that keeps the published events in memory, intended for tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryPublisher creates an empty MemoryPublisher.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish stores the event.
+func (p *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+// Events returns a copy of the published events, in publication order.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
+
+// Reset removes all the published events.
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = nil
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RelayOptions configures a Relay.
+type RelayOptions struct {
+	BatchSize   int           // Number of events read at once, defaults to 100.
+	Interval    time.Duration // Delay between polls when no event is pending, defaults to 1 second.
+	MaxAttempts int           // Failed publications after which an event is dead, defaults to 10.
+}
+
+// Relay reads the pending events of the outbox and delivers them through a Publisher.
+//
+// Events are published in the order they were stored. When the publication of an event fails,
+// the error is recorded in the event and the batch stops, so the event is retried before the
+// next ones in the following poll. After MaxAttempts failures the event is dead: it is skipped by
+// the next polls, so it no longer holds back the later events, and is listed by Dead. Resetting its
+// attempts to 0 publishes it again. Since an event is marked as sent only after it is published,
+// a failure between both steps delivers the event again: delivery is at least once.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	options   RelayOptions
+}
+
+// NewRelay creates a Relay reading the outbox table of the given database.
+//
+// Usage:
+// relay := outbox.NewRelay(db, publisher, outbox.RelayOptions{BatchSize: 50})
+func NewRelay(db *gorm.DB, publisher Publisher, options RelayOptions) *Relay {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 10
+	}
+
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		options:   options,
+	}
+}
+
+// Run polls the outbox and publishes the pending events until the context is canceled.
+// It returns the error of the context, or the first error raised while reading or updating the outbox.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		published, err := r.ProcessBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		// A full batch means there are probably more events waiting.
+		if published == r.options.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.options.Interval):
+		}
+	}
+}
+
+// ProcessBatch publishes up to BatchSize pending events and returns how many were published.
+// A publication error is recorded in the event and is not returned, only database errors are.
+// Dead events are skipped.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	if r.publisher == nil {
+		return 0, errors.New("the publisher should not be nil")
+	}
+
+	db := r.db.WithContext(ctx)
+
+	var events []Event
+	err := db.Where("sent_at IS NULL AND attempts < ?", r.options.MaxAttempts).Order("id").Limit(r.options.BatchSize).Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			update := map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": err.Error(),
+			}
+
+			return published, db.Model(&Event{}).Where("id = ?", event.ID).Updates(update).Error
+		}
+
+		if err := db.Model(&Event{}).Where("id = ?", event.ID).Update("sent_at", time.Now()).Error; err != nil {
+			return published, err
+		}
+
+		published++
+	}
+
+	return published, nil
+}
+
+// Pending returns the number of events not published yet, dead events excluded.
+func (r *Relay) Pending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Event{}).
+		Where("sent_at IS NULL AND attempts < ?", r.options.MaxAttempts).Count(&count).Error
+
+	return count, err
+}
+
+// Dead returns the events whose publication failed MaxAttempts times, in the order they were stored.
+func (r *Relay) Dead(ctx context.Context) ([]Event, error) {
+	events := make([]Event, 0)
+	err := r.db.WithContext(ctx).Where("sent_at IS NULL AND attempts >= ?", r.options.MaxAttempts).
+		Order("id").Find(&events).Error
+
+	return events, err
+}
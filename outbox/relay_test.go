@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// failingPublisher fails to publish the events until it is enabled.
+type failingPublisher struct {
+	enabled bool
+	inner   *MemoryPublisher
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event Event) error {
+	if !p.enabled {
+		return errors.New("broker unavailable")
+	}
+
+	return p.inner.Publish(ctx, event)
+}
+
+// resetOutbox marks all the events stored by previous tests as sent.
+func resetOutbox(t *testing.T, db *gorm.DB) {
+	err := db.Model(&Event{}).Where("sent_at IS NULL").Update("sent_at", time.Now()).Error
+	assert.Nil(t, err)
+}
+
+func TestRelay_ProcessBatch(t *testing.T) {
+	db := getGormConnection(t)
+	ctx := context.Background()
+
+	t.Run("Publish pending events in order", func(t *testing.T) {
+		resetOutbox(t, db)
+
+		ids := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+		for _, id := range ids {
+			assert.Nil(t, Enqueue(db, "orders", id, EventCreated, nil))
+		}
+
+		publisher := NewMemoryPublisher()
+		relay := NewRelay(db, publisher, RelayOptions{BatchSize: 2})
+
+		published, err := relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, published)
+
+		published, err = relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, published)
+
+		events := publisher.Events()
+		assert.Len(t, events, 3)
+		for i, id := range ids {
+			assert.Equal(t, id, events[i].AggregateID)
+		}
+
+		pending, err := relay.Pending(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), pending)
+	})
+
+	t.Run("Failed publication is retried", func(t *testing.T) {
+		resetOutbox(t, db)
+
+		id := uuid.NewString()
+		assert.Nil(t, Enqueue(db, "orders", id, EventCreated, nil))
+
+		publisher := &failingPublisher{inner: NewMemoryPublisher()}
+		relay := NewRelay(db, publisher, RelayOptions{})
+
+		published, err := relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, published)
+
+		var event Event
+		assert.Nil(t, db.Where("aggregate_id = ?", id).First(&event).Error)
+		assert.Equal(t, 1, event.Attempts)
+		assert.Equal(t, "broker unavailable", event.LastError)
+
+		publisher.enabled = true
+
+		published, err = relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, id, publisher.inner.Events()[0].AggregateID)
+	})
+
+	t.Run("Dead event no longer blocks the next ones", func(t *testing.T) {
+		resetOutbox(t, db)
+
+		dead, next := uuid.NewString(), uuid.NewString()
+		assert.Nil(t, Enqueue(db, "orders", dead, EventCreated, nil))
+		assert.Nil(t, Enqueue(db, "orders", next, EventCreated, nil))
+
+		publisher := &failingPublisher{inner: NewMemoryPublisher()}
+		relay := NewRelay(db, publisher, RelayOptions{MaxAttempts: 2})
+
+		for i := 0; i < 2; i++ {
+			published, err := relay.ProcessBatch(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, published)
+		}
+
+		events, err := relay.Dead(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, events, 1)
+		assert.Equal(t, dead, events[0].AggregateID)
+		assert.Equal(t, 2, events[0].Attempts)
+
+		pending, err := relay.Pending(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), pending)
+
+		// The next event fails in turn, without being held back by the dead one.
+		published, err := relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, published)
+
+		var event Event
+		assert.Nil(t, db.Where("aggregate_id = ?", next).First(&event).Error)
+		assert.Equal(t, 1, event.Attempts)
+
+		publisher.enabled = true
+
+		published, err = relay.ProcessBatch(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, next, publisher.inner.Events()[0].AggregateID)
+	})
+
+	t.Run("Nil publisher", func(t *testing.T) {
+		_, err := NewRelay(db, nil, RelayOptions{}).ProcessBatch(ctx)
+		assert.Equal(t, "the publisher should not be nil", err.Error())
+	})
+}
+
+func TestRelay_Run(t *testing.T) {
+	db := getGormConnection(t)
+	resetOutbox(t, db)
+
+	id := uuid.NewString()
+	assert.Nil(t, Enqueue(db, "orders", id, EventCreated, nil))
+
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(db, publisher, RelayOptions{Interval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := relay.Run(ctx)
+	assert.NotNil(t, err)
+	assert.Len(t, publisher.Events(), 1)
+	assert.Equal(t, id, publisher.Events()[0].AggregateID)
+}
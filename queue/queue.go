@@ -0,0 +1,296 @@
+// Package queue implements a database-backed job queue on top of the repositories of gormet.
+//
+// Jobs are stored in a single table shared by all the queues, with their payload encoded as JSON.
+// Workers claim the due jobs atomically, so a job is handed to one worker at a time, and a claimed
+// job becomes visible again when its visibility timeout expires, for instance after a worker crash.
+// Failed jobs are retried with an exponential backoff until their maximum number of attempts, and
+// are then kept in the dead state until they are requeued. Jobs are processed at least once:
+// handlers must tolerate duplicates.
+//
+// Usage:
+//
+//	if err := queue.AutoMigrate(db); err != nil {
+//		// Handle error
+//	}
+//
+//	emails, err := queue.New[Email](db, "emails", queue.Options{MaxAttempts: 5})
+//	if err != nil {
+//		// Handle error
+//	}
+//
+//	job, err := emails.Enqueue(Email{To: "john@example.com"}, queue.Delay(time.Minute))
+//
+//	go emails.Work(ctx, sendEmail, queue.WorkerOptions{Concurrency: 4})
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"gorm.io/gorm"
+)
+
+// Status is the state of a job.
+type Status string
+
+const (
+	StatusPending Status = "pending" // Waiting to be claimed once its run time is reached.
+	StatusRunning Status = "running" // Claimed by a worker until its visibility timeout.
+	StatusDone    Status = "done"    // Processed successfully.
+	StatusDead    Status = "dead"    // Failed on all its attempts, kept until requeued.
+)
+
+// Job is a record of the jobs table holding a payload of type P.
+type Job[P any] struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Queue       string     `json:"queue" gorm:"not null;index:idx_queue_jobs_due,priority:1"` // The name of the queue.
+	Status      Status     `json:"status" gorm:"not null;index:idx_queue_jobs_due,priority:2"`
+	RunAt       time.Time  `json:"runAt" gorm:"not null;index:idx_queue_jobs_due,priority:3"` // When the job becomes due.
+	Priority    int        `json:"priority" gorm:"not null"`                                  // Jobs with a higher priority are claimed first.
+	Payload     P          `json:"payload" gorm:"serializer:json;type:text"`
+	Attempts    int        `json:"attempts" gorm:"not null"`    // Number of times the job was claimed.
+	MaxAttempts int        `json:"maxAttempts" gorm:"not null"` // Number of attempts before the job is dead.
+	LastError   string     `json:"lastError"`                   // Error of the last failed attempt.
+	ClaimToken  string     `json:"-" gorm:"index"`              // Token of the current claim, empty when not claimed.
+	LockedUntil *time.Time `json:"lockedUntil"`                 // End of the visibility timeout of the current claim.
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	FinishedAt  *time.Time `json:"finishedAt"` // When the job was done or declared dead.
+}
+
+// TableName returns the name of the jobs table.
+func (Job[P]) TableName() string {
+	return "queue_jobs"
+}
+
+// AutoMigrate creates or updates the jobs table.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Job[json.RawMessage]{})
+}
+
+// ClaimStrategy defines how workers claim the due jobs without handing a job to two workers.
+type ClaimStrategy int
+
+const (
+	// ClaimAuto uses ClaimSkipLocked on PostgreSQL and MySQL, and ClaimToken on the other dialects.
+	ClaimAuto ClaimStrategy = iota
+
+	// ClaimSkipLocked selects the due jobs with FOR UPDATE SKIP LOCKED inside a transaction, so
+	// concurrent workers never wait for each other nor select the same jobs.
+	ClaimSkipLocked
+
+	// ClaimToken selects the due jobs without locks and claims them with a conditional update
+	// writing a unique claim token; the jobs claimed concurrently by another worker are skipped.
+	ClaimToken
+)
+
+// Options configures a Queue.
+type Options struct {
+	MaxAttempts       int           // Default number of attempts of the jobs, defaults to 3.
+	InitialBackoff    time.Duration // Delay before the first retry, doubled after each attempt, defaults to 1 second.
+	MaxBackoff        time.Duration // Upper bound of the delay between attempts, defaults to 1 hour.
+	VisibilityTimeout time.Duration // Time a claimed job is hidden from the other workers, defaults to 5 minutes.
+	Claim             ClaimStrategy // How the due jobs are claimed.
+
+	// Repository holds the options of the repository storing the jobs, such as gormet.WithRetry
+	// to retry the claims failing with "database is locked" on SQLite.
+	Repository []gormet.Option
+}
+
+// Queue stores and hands out the jobs of a named queue with payloads of type P.
+type Queue[P any] struct {
+	name    string
+	repo    *gormet.Repository[Job[P]]
+	options Options
+}
+
+// New creates the queue with the given name, storing its jobs in the jobs table of the database.
+//
+// Usage:
+// emails, err := queue.New[Email](db, "emails", queue.Options{VisibilityTimeout: time.Minute})
+//
+// Parameters:
+// - db: The database holding the jobs table, created by AutoMigrate.
+// - name: The name of the queue, distinguishing its jobs from the ones of the other queues.
+// - options: The configuration of the queue; zero values are replaced by the defaults.
+//
+// Returns:
+// - The queue.
+// - An error if the name is empty, if an option is invalid or if the repository can't be created.
+func New[P any](db *gorm.DB, name string, options Options) (*Queue[P], error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("the queue name should not be empty")
+	}
+
+	if options.MaxAttempts < 0 || options.InitialBackoff < 0 || options.MaxBackoff < 0 || options.VisibilityTimeout < 0 {
+		return nil, errors.New("the queue options should not be negative")
+	}
+
+	if options.Claim < ClaimAuto || options.Claim > ClaimToken {
+		return nil, fmt.Errorf("unknown claim strategy: %d", options.Claim)
+	}
+
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = 3
+	}
+
+	if options.InitialBackoff == 0 {
+		options.InitialBackoff = time.Second
+	}
+
+	if options.MaxBackoff == 0 {
+		options.MaxBackoff = time.Hour
+	}
+
+	if options.VisibilityTimeout == 0 {
+		options.VisibilityTimeout = 5 * time.Minute
+	}
+
+	if options.Claim == ClaimAuto {
+		switch db.Dialector.Name() {
+		case "postgres", "mysql":
+			options.Claim = ClaimSkipLocked
+		default:
+			options.Claim = ClaimToken
+		}
+	}
+
+	repo, err := gormet.New[Job[P]](db, options.Repository...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Queue[P]{
+		name:    name,
+		repo:    repo,
+		options: options,
+	}, nil
+}
+
+// EnqueueOption customizes a job stored by Enqueue.
+type EnqueueOption func(*enqueueConfig)
+
+// enqueueConfig holds the settings assembled from the enqueue options.
+type enqueueConfig struct {
+	runAt       time.Time
+	priority    int
+	maxAttempts int
+}
+
+// RunAt makes the job due at the given time instead of immediately.
+func RunAt(t time.Time) EnqueueOption {
+	return func(c *enqueueConfig) {
+		c.runAt = t
+	}
+}
+
+// Delay makes the job due after the given delay instead of immediately.
+func Delay(d time.Duration) EnqueueOption {
+	return func(c *enqueueConfig) {
+		c.runAt = time.Now().Add(d)
+	}
+}
+
+// Priority sets the priority of the job. Among the due jobs, the ones with a higher priority are
+// claimed first, then the oldest ones. The default priority is 0.
+func Priority(priority int) EnqueueOption {
+	return func(c *enqueueConfig) {
+		c.priority = priority
+	}
+}
+
+// MaxAttempts overrides the number of attempts of the job set in the options of the queue.
+func MaxAttempts(attempts int) EnqueueOption {
+	return func(c *enqueueConfig) {
+		c.maxAttempts = attempts
+	}
+}
+
+// Enqueue stores a new pending job with the given payload.
+//
+// Usage:
+// job, err := emails.Enqueue(Email{To: "john@example.com"}, queue.Priority(10), queue.Delay(time.Minute))
+//
+// Parameters:
+// - payload: The payload of the job, encoded as JSON.
+// - opts: Optional settings, such as RunAt, Delay, Priority and MaxAttempts.
+//
+// Returns:
+// - The stored job.
+// - An error if the max attempts is not positive or if the job can't be stored.
+func (q *Queue[P]) Enqueue(payload P, opts ...EnqueueOption) (*Job[P], error) {
+	cfg := enqueueConfig{
+		runAt:       time.Now(),
+		maxAttempts: q.options.MaxAttempts,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	if cfg.maxAttempts < 1 {
+		return nil, errors.New("the max attempts should be at least 1")
+	}
+
+	job := &Job[P]{
+		Queue:       q.name,
+		Status:      StatusPending,
+		RunAt:       cfg.runAt,
+		Priority:    cfg.priority,
+		Payload:     payload,
+		MaxAttempts: cfg.maxAttempts,
+	}
+
+	if err := q.repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Get retrieves the job with the given ID.
+func (q *Queue[P]) Get(id uint) (*Job[P], error) {
+	return q.repo.Get(Job[P]{ID: id, Queue: q.name})
+}
+
+// Count returns the number of jobs of the queue in the given status.
+func (q *Queue[P]) Count(status Status) (int64, error) {
+	return q.repo.Count(gormet.And(
+		gormet.Eq[Job[P]]("queue", q.name),
+		gormet.Eq[Job[P]]("status", status),
+	))
+}
+
+// Requeue makes a dead job pending again, due immediately and with a new set of attempts.
+//
+// Returns:
+// - nil if the job is requeued.
+// - gorm.ErrRecordNotFound if the queue has no dead job with the given ID.
+func (q *Queue[P]) Requeue(id uint) error {
+	affected, err := q.repo.UpdateWhere(gormet.And(
+		gormet.Eq[Job[P]]("id", id),
+		gormet.Eq[Job[P]]("queue", q.name),
+		gormet.Eq[Job[P]]("status", StatusDead),
+	), map[string]interface{}{
+		"status":      StatusPending,
+		"run_at":      time.Now(),
+		"attempts":    0,
+		"finished_at": nil,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type testEmail struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+func getGormConnection(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("./database.db"), &gorm.Config{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, AutoMigrate(db))
+
+	return db
+}
+
+// newQueue creates a queue with a unique name, so tests don't see the jobs of each other.
+func newQueue(t *testing.T, db *gorm.DB, options Options) *Queue[testEmail] {
+	q, err := New[testEmail](db, uuid.NewString(), options)
+	assert.Nil(t, err)
+
+	return q
+}
+
+func TestNew(t *testing.T) {
+	db := getGormConnection(t)
+
+	t.Run("Defaults", func(t *testing.T) {
+		q := newQueue(t, db, Options{})
+
+		assert.Equal(t, 3, q.options.MaxAttempts)
+		assert.Equal(t, time.Second, q.options.InitialBackoff)
+		assert.Equal(t, time.Hour, q.options.MaxBackoff)
+		assert.Equal(t, 5*time.Minute, q.options.VisibilityTimeout)
+		assert.Equal(t, ClaimToken, q.options.Claim)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := New[testEmail](db, " ", Options{})
+		assert.Equal(t, "the queue name should not be empty", err.Error())
+
+		_, err = New[testEmail](db, "emails", Options{MaxAttempts: -1})
+		assert.NotNil(t, err)
+
+		_, err = New[testEmail](db, "emails", Options{Claim: ClaimStrategy(42)})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	db := getGormConnection(t)
+
+	t.Run("Store pending job", func(t *testing.T) {
+		q := newQueue(t, db, Options{MaxAttempts: 5})
+
+		job, err := q.Enqueue(testEmail{To: "john@example.com", Subject: "hello"})
+		assert.Nil(t, err)
+		assert.NotZero(t, job.ID)
+
+		stored, err := q.Get(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, StatusPending, stored.Status)
+		assert.Equal(t, "john@example.com", stored.Payload.To)
+		assert.Equal(t, 5, stored.MaxAttempts)
+		assert.Equal(t, 0, stored.Attempts)
+
+		count, err := q.Count(StatusPending)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		q := newQueue(t, db, Options{})
+		runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+		job, err := q.Enqueue(testEmail{}, RunAt(runAt), Priority(7), MaxAttempts(1))
+		assert.Nil(t, err)
+		assert.True(t, runAt.Equal(job.RunAt))
+		assert.Equal(t, 7, job.Priority)
+		assert.Equal(t, 1, job.MaxAttempts)
+
+		job, err = q.Enqueue(testEmail{}, Delay(time.Minute))
+		assert.Nil(t, err)
+		assert.True(t, job.RunAt.After(time.Now().Add(50*time.Second)))
+
+		_, err = q.Enqueue(testEmail{}, MaxAttempts(0))
+		assert.Equal(t, "the max attempts should be at least 1", err.Error())
+	})
+
+	t.Run("Jobs of other queues", func(t *testing.T) {
+		q := newQueue(t, db, Options{})
+		other := newQueue(t, db, Options{})
+
+		job, err := other.Enqueue(testEmail{})
+		assert.Nil(t, err)
+
+		_, err = q.Get(job.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestQueue_Requeue(t *testing.T) {
+	db := getGormConnection(t)
+	q := newQueue(t, db, Options{MaxAttempts: 1})
+
+	job, err := q.Enqueue(testEmail{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, gorm.ErrRecordNotFound, q.Requeue(job.ID))
+
+	jobs, err := q.Claim(1)
+	assert.Nil(t, err)
+	assert.Nil(t, q.Fail(&jobs[0], assert.AnError))
+	assert.Equal(t, StatusDead, jobs[0].Status)
+
+	assert.Nil(t, q.Requeue(job.ID))
+
+	stored, err := q.Get(job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, 0, stored.Attempts)
+	assert.Nil(t, stored.FinishedAt)
+}
@@ -0,0 +1,370 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gsdenys/gormet"
+	"gorm.io/gorm"
+)
+
+// ErrClaimLost is returned when a job can't be completed, failed or extended because its claim
+// expired and the job was claimed again by another worker.
+var ErrClaimLost = errors.New("the claim of the job was lost")
+
+// Handler processes the payload of a job. Returning an error, or panicking, fails the attempt.
+type Handler[P any] func(ctx context.Context, job *Job[P]) error
+
+// WorkerOptions configures the workers started by Work.
+type WorkerOptions struct {
+	Concurrency  int           // Number of jobs processed in parallel, defaults to 1.
+	PollInterval time.Duration // Delay between polls when no job is due, defaults to 1 second.
+}
+
+// dueCondition matches the pending jobs whose run time is reached and the running jobs whose
+// visibility timeout expired, as long as they have attempts left.
+const dueCondition = "queue = ? AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ? AND attempts < max_attempts))"
+
+// expiredCondition matches the running jobs whose visibility timeout expired after their last attempt.
+const expiredCondition = "queue = ? AND status = ? AND locked_until < ? AND attempts >= max_attempts"
+
+// errTimeout is recorded as the last error of a job whose worker never acknowledged its last attempt.
+const errTimeout = "the visibility timeout expired"
+
+// claimOrder is the order in which the due jobs are claimed.
+const claimOrder = "priority DESC, run_at ASC, id ASC"
+
+// Claim atomically claims up to n due jobs, in order of priority then run time, and returns them.
+//
+// The claimed jobs are running, their attempts are incremented and they stay hidden from the other
+// workers until their visibility timeout expires. Each job must then be completed with Complete,
+// failed with Fail, or kept longer with Touch. A job whose timeout expires is claimed again, unless
+// it was its last attempt: it is then declared dead, so a job crashing its workers is not retried forever.
+//
+// Usage:
+// jobs, err := emails.Claim(10)
+//
+// Parameters:
+// - n: The maximum number of jobs to claim.
+//
+// Returns:
+// - The claimed jobs, empty when no job is due.
+// - An error if the jobs table can't be read or updated.
+func (q *Queue[P]) Claim(n int) ([]Job[P], error) {
+	if n <= 0 {
+		return []Job[P]{}, nil
+	}
+
+	if q.options.Claim == ClaimSkipLocked {
+		var jobs []Job[P]
+
+		err := q.repo.Transaction(func(tx *gormet.Repository[Job[P]]) error {
+			var err error
+			jobs, err = q.claim(tx, n, gormet.SearchLock(gormet.LockForUpdate, gormet.LockSkipLocked))
+
+			return err
+		})
+
+		return jobs, err
+	}
+
+	return q.claim(q.repo, n)
+}
+
+// claim selects up to n due jobs and claims them with a conditional update writing a new claim token,
+// so the jobs claimed concurrently by another worker no longer match and are skipped.
+func (q *Queue[P]) claim(repo *gormet.Repository[Job[P]], n int, opts ...gormet.SearchOption) ([]Job[P], error) {
+	now := time.Now()
+
+	if err := q.expire(repo, now); err != nil {
+		return []Job[P]{}, err
+	}
+
+	due := []interface{}{q.name, StatusPending, now, StatusRunning, now}
+
+	opts = append([]gormet.SearchOption{
+		gormet.SearchPageSize(uint(n)), gormet.SearchSort(claimOrder), gormet.SearchSelect("id"),
+	}, opts...)
+
+	candidates, err := repo.SearchSliceWith(1, opts, dueCondition, due...)
+	if err != nil || len(candidates.Entities) == 0 {
+		return []Job[P]{}, err
+	}
+
+	ids := make([]uint, len(candidates.Entities))
+	for i, job := range candidates.Entities {
+		ids[i] = job.ID
+	}
+
+	token := uuid.NewString()
+
+	_, err = repo.UpdateWhere("id IN ? AND ("+dueCondition+")", map[string]interface{}{
+		"status":       StatusRunning,
+		"claim_token":  token,
+		"locked_until": now.Add(q.options.VisibilityTimeout),
+		"attempts":     gorm.Expr("attempts + 1"),
+	}, append([]interface{}{ids}, due...)...)
+
+	if err != nil {
+		return []Job[P]{}, err
+	}
+
+	return repo.SearchAllWith([]gormet.SearchOption{gormet.SearchSort(claimOrder)}, "claim_token = ?", token)
+}
+
+// expire declares dead the running jobs whose visibility timeout expired after their last attempt.
+func (q *Queue[P]) expire(repo *gormet.Repository[Job[P]], now time.Time) error {
+	_, err := repo.UpdateWhere(expiredCondition, map[string]interface{}{
+		"status":      StatusDead,
+		"finished_at": now,
+		"last_error":  errTimeout,
+	}, q.name, StatusRunning, now)
+
+	return err
+}
+
+// Complete marks a claimed job as done.
+//
+// Returns:
+// - nil if the job is done.
+// - ErrClaimLost if the job was claimed again by another worker, or an error if the job can't be updated.
+func (q *Queue[P]) Complete(job *Job[P]) error {
+	now := time.Now()
+
+	return q.release(job, map[string]interface{}{
+		"status":      StatusDone,
+		"finished_at": now,
+	})
+}
+
+// Fail records the failure of an attempt of a claimed job. The job is retried after a backoff doubling
+// after each attempt, or declared dead when it reached its max attempts.
+//
+// Returns:
+// - nil if the failure is recorded.
+// - ErrClaimLost if the job was claimed again by another worker, or an error if the job can't be updated.
+func (q *Queue[P]) Fail(job *Job[P], cause error) error {
+	values := map[string]interface{}{
+		"last_error": fmt.Sprint(cause),
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		values["status"] = StatusDead
+		values["finished_at"] = time.Now()
+	} else {
+		values["status"] = StatusPending
+		values["run_at"] = time.Now().Add(q.backoff(job.Attempts))
+	}
+
+	return q.release(job, values)
+}
+
+// Touch extends the visibility timeout of a claimed job, so a long running handler keeps its claim.
+//
+// Returns:
+// - nil if the claim is extended.
+// - ErrClaimLost if the job was claimed again by another worker, or an error if the job can't be updated.
+func (q *Queue[P]) Touch(job *Job[P]) error {
+	lockedUntil := time.Now().Add(q.options.VisibilityTimeout)
+
+	if err := q.updateClaimed(job, map[string]interface{}{"locked_until": lockedUntil}); err != nil {
+		return err
+	}
+
+	job.LockedUntil = &lockedUntil
+	return nil
+}
+
+// abandon makes a claimed job pending again without counting the attempt, when the worker stops before processing it.
+func (q *Queue[P]) abandon(job *Job[P]) error {
+	return q.release(job, map[string]interface{}{
+		"status":   StatusPending,
+		"attempts": gorm.Expr("attempts - 1"),
+	})
+}
+
+// release ends the claim of a job, applying the given values.
+func (q *Queue[P]) release(job *Job[P], values map[string]interface{}) error {
+	values["claim_token"] = ""
+	values["locked_until"] = nil
+
+	if err := q.updateClaimed(job, values); err != nil {
+		return err
+	}
+
+	// The job is reloaded, so it reflects the values computed by the database.
+	stored, err := q.Get(job.ID)
+	if err != nil {
+		return err
+	}
+
+	*job = *stored
+	return nil
+}
+
+// updateClaimed applies the values to the job, as long as it is still claimed with the same token.
+func (q *Queue[P]) updateClaimed(job *Job[P], values map[string]interface{}) error {
+	if job == nil {
+		return errors.New("the job should not be nil")
+	}
+
+	affected, err := q.repo.UpdateWhere(gormet.And(
+		gormet.Eq[Job[P]]("id", job.ID),
+		gormet.Eq[Job[P]]("status", StatusRunning),
+		gormet.Eq[Job[P]]("claim_token", job.ClaimToken),
+	), values)
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrClaimLost
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (q *Queue[P]) backoff(attempt int) time.Duration {
+	delay := q.options.InitialBackoff
+
+	for i := 1; i < attempt && delay < q.options.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, q.options.MaxBackoff)
+}
+
+// Process claims up to n due jobs and runs the handler on each of them in turn, completing or failing
+// them according to its result. When the context is canceled, the jobs not processed yet are released.
+//
+// Returns:
+// - The number of jobs processed, whether they succeeded or failed.
+// - The error of the context, or an error if the jobs table can't be read or updated.
+func (q *Queue[P]) Process(ctx context.Context, handler Handler[P], n int) (int, error) {
+	if handler == nil {
+		return 0, errors.New("the handler should not be nil")
+	}
+
+	jobs, err := q.Claim(n)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+
+	for i := range jobs {
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(jobs); j++ {
+				_ = q.abandon(&jobs[j])
+			}
+
+			return processed, err
+		}
+
+		if err := q.handle(ctx, handler, &jobs[i]); err != nil && !errors.Is(err, ErrClaimLost) {
+			return processed, err
+		}
+
+		processed++
+	}
+
+	return processed, nil
+}
+
+// handle runs the handler on a claimed job and records its result.
+func (q *Queue[P]) handle(ctx context.Context, handler Handler[P], job *Job[P]) error {
+	if cause := run(ctx, handler, job); cause != nil {
+		return q.Fail(job, cause)
+	}
+
+	return q.Complete(job)
+}
+
+// run invokes the handler, converting a panic into an error.
+func run[P any](ctx context.Context, handler Handler[P], job *Job[P]) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("the job panicked: %v", p)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+// Work starts the workers processing the jobs of the queue until the context is canceled. Each worker
+// claims one job at a time, and waits for the poll interval when no job is due.
+//
+// Usage:
+//
+//	err := emails.Work(ctx, func(ctx context.Context, job *queue.Job[Email]) error {
+//		return mailer.Send(ctx, job.Payload)
+//	}, queue.WorkerOptions{Concurrency: 4})
+//
+// Returns:
+// - The error of the context, or the first error raised while reading or updating the jobs table,
+// after all the workers stopped.
+func (q *Queue[P]) Work(ctx context.Context, handler Handler[P], options WorkerOptions) error {
+	if handler == nil {
+		return errors.New("the handler should not be nil")
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := q.work(ctx, handler, options.PollInterval); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+// work is the loop of a single worker.
+func (q *Queue[P]) work(ctx context.Context, handler Handler[P], interval time.Duration) error {
+	for {
+		processed, err := q.Process(ctx, handler, 1)
+		if err != nil {
+			return err
+		}
+
+		if processed > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gsdenys/gormet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_Claim(t *testing.T) {
+	db := getGormConnection(t)
+
+	for name, strategy := range map[string]ClaimStrategy{"token": ClaimToken, "skip locked": ClaimSkipLocked} {
+		t.Run("Order and due time with "+name, func(t *testing.T) {
+			q := newQueue(t, db, Options{Claim: strategy})
+
+			low, _ := q.Enqueue(testEmail{Subject: "low"})
+			high, _ := q.Enqueue(testEmail{Subject: "high"}, Priority(10))
+			_, _ = q.Enqueue(testEmail{Subject: "later"}, Delay(time.Hour))
+			next, _ := q.Enqueue(testEmail{Subject: "next"})
+
+			jobs, err := q.Claim(10)
+			assert.Nil(t, err)
+			assert.Len(t, jobs, 3)
+			assert.Equal(t, []uint{high.ID, low.ID, next.ID}, []uint{jobs[0].ID, jobs[1].ID, jobs[2].ID})
+
+			for _, job := range jobs {
+				assert.Equal(t, StatusRunning, job.Status)
+				assert.Equal(t, 1, job.Attempts)
+				assert.NotEmpty(t, job.ClaimToken)
+				assert.NotNil(t, job.LockedUntil)
+			}
+
+			jobs, err = q.Claim(10)
+			assert.Nil(t, err)
+			assert.Empty(t, jobs)
+		})
+
+		t.Run("Limit with "+name, func(t *testing.T) {
+			q := newQueue(t, db, Options{Claim: strategy})
+
+			for i := 0; i < 5; i++ {
+				_, err := q.Enqueue(testEmail{})
+				assert.Nil(t, err)
+			}
+
+			jobs, err := q.Claim(2)
+			assert.Nil(t, err)
+			assert.Len(t, jobs, 2)
+
+			count, err := q.Count(StatusPending)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(3), count)
+		})
+	}
+
+	t.Run("Visibility timeout", func(t *testing.T) {
+		q := newQueue(t, db, Options{VisibilityTimeout: 20 * time.Millisecond})
+
+		_, err := q.Enqueue(testEmail{})
+		assert.Nil(t, err)
+
+		first, err := q.Claim(1)
+		assert.Nil(t, err)
+		assert.Len(t, first, 1)
+
+		time.Sleep(30 * time.Millisecond)
+
+		second, err := q.Claim(1)
+		assert.Nil(t, err)
+		assert.Len(t, second, 1)
+		assert.Equal(t, 2, second[0].Attempts)
+
+		assert.Equal(t, ErrClaimLost, q.Complete(&first[0]))
+		assert.Equal(t, ErrClaimLost, q.Touch(&first[0]))
+		assert.Nil(t, q.Complete(&second[0]))
+	})
+
+	t.Run("Expired last attempt", func(t *testing.T) {
+		q := newQueue(t, db, Options{VisibilityTimeout: 10 * time.Millisecond, MaxAttempts: 2})
+
+		job, err := q.Enqueue(testEmail{})
+		assert.Nil(t, err)
+
+		// The handler never acknowledges the job, as if its worker crashed on every attempt.
+		for attempt := 1; attempt <= 2; attempt++ {
+			jobs, err := q.Claim(1)
+			assert.Nil(t, err)
+			assert.Len(t, jobs, 1)
+			assert.Equal(t, attempt, jobs[0].Attempts)
+
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		jobs, err := q.Claim(1)
+		assert.Nil(t, err)
+		assert.Empty(t, jobs)
+
+		stored, err := q.Get(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, StatusDead, stored.Status)
+		assert.Equal(t, 2, stored.Attempts)
+		assert.Equal(t, "the visibility timeout expired", stored.LastError)
+		assert.NotNil(t, stored.FinishedAt)
+
+		// A requeued job gets its attempts back.
+		assert.Nil(t, q.Requeue(job.ID))
+
+		jobs, err = q.Claim(1)
+		assert.Nil(t, err)
+		assert.Len(t, jobs, 1)
+	})
+
+	t.Run("Concurrent claims", func(t *testing.T) {
+		policy := gormet.DefaultRetryPolicy()
+		policy.MaxAttempts = 50
+		policy.InitialBackoff = time.Millisecond
+
+		q := newQueue(t, db, Options{Repository: []gormet.Option{gormet.WithRetry(policy)}})
+
+		for i := 0; i < 20; i++ {
+			_, err := q.Enqueue(testEmail{})
+			assert.Nil(t, err)
+		}
+
+		var mu sync.Mutex
+		claimed := make(map[uint]int)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for {
+					jobs, err := q.Claim(3)
+					assert.Nil(t, err)
+
+					if len(jobs) == 0 {
+						return
+					}
+
+					mu.Lock()
+					for _, job := range jobs {
+						claimed[job.ID]++
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Len(t, claimed, 20)
+		for id, n := range claimed {
+			assert.Equal(t, 1, n, "job %d", id)
+		}
+	})
+}
+
+func TestQueue_Fail(t *testing.T) {
+	db := getGormConnection(t)
+
+	t.Run("Retry with backoff then dead", func(t *testing.T) {
+		q := newQueue(t, db, Options{MaxAttempts: 2, InitialBackoff: time.Hour})
+
+		job, err := q.Enqueue(testEmail{})
+		assert.Nil(t, err)
+
+		jobs, err := q.Claim(1)
+		assert.Nil(t, err)
+		assert.Nil(t, q.Fail(&jobs[0], errors.New("smtp unavailable")))
+
+		failed := jobs[0]
+		assert.Equal(t, StatusPending, failed.Status)
+		assert.Equal(t, "smtp unavailable", failed.LastError)
+		assert.Empty(t, failed.ClaimToken)
+		assert.True(t, failed.RunAt.After(time.Now().Add(59*time.Minute)))
+
+		// Not due before the backoff elapses.
+		jobs, err = q.Claim(1)
+		assert.Nil(t, err)
+		assert.Empty(t, jobs)
+
+		assert.Nil(t, db.Model(&Job[testEmail]{}).Where("id = ?", job.ID).Update("run_at", time.Now()).Error)
+
+		jobs, err = q.Claim(1)
+		assert.Nil(t, err)
+		assert.Nil(t, q.Fail(&jobs[0], errors.New("smtp unavailable")))
+		assert.Equal(t, StatusDead, jobs[0].Status)
+		assert.NotNil(t, jobs[0].FinishedAt)
+
+		count, err := q.Count(StatusDead)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		q := newQueue(t, db, Options{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+		assert.Equal(t, time.Second, q.backoff(1))
+		assert.Equal(t, 2*time.Second, q.backoff(2))
+		assert.Equal(t, 4*time.Second, q.backoff(3))
+		assert.Equal(t, 5*time.Second, q.backoff(4))
+		assert.Equal(t, 5*time.Second, q.backoff(100))
+	})
+}
+
+func TestQueue_Process(t *testing.T) {
+	db := getGormConnection(t)
+	ctx := context.Background()
+
+	t.Run("Complete, fail and recover panics", func(t *testing.T) {
+		q := newQueue(t, db, Options{MaxAttempts: 1})
+
+		for _, subject := range []string{"ok", "error", "panic"} {
+			_, err := q.Enqueue(testEmail{Subject: subject})
+			assert.Nil(t, err)
+		}
+
+		processed, err := q.Process(ctx, func(ctx context.Context, job *Job[testEmail]) error {
+			switch job.Payload.Subject {
+			case "error":
+				return errors.New("failed")
+			case "panic":
+				panic("boom")
+			}
+
+			return nil
+		}, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, processed)
+
+		done, _ := q.Count(StatusDone)
+		dead, _ := q.Count(StatusDead)
+		assert.Equal(t, int64(1), done)
+		assert.Equal(t, int64(2), dead)
+	})
+
+	t.Run("Canceled context releases the jobs", func(t *testing.T) {
+		q := newQueue(t, db, Options{})
+
+		for i := 0; i < 2; i++ {
+			_, err := q.Enqueue(testEmail{})
+			assert.Nil(t, err)
+		}
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		processed, err := q.Process(canceled, func(ctx context.Context, job *Job[testEmail]) error { return nil }, 10)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 0, processed)
+
+		jobs, err := q.Claim(10)
+		assert.Nil(t, err)
+		assert.Len(t, jobs, 2)
+		assert.Equal(t, 1, jobs[0].Attempts)
+	})
+
+	t.Run("Nil handler", func(t *testing.T) {
+		q := newQueue(t, db, Options{})
+
+		_, err := q.Process(ctx, nil, 1)
+		assert.NotNil(t, err)
+		assert.NotNil(t, q.Work(ctx, nil, WorkerOptions{}))
+	})
+}
+
+func TestQueue_Work(t *testing.T) {
+	db := getGormConnection(t)
+
+	policy := gormet.DefaultRetryPolicy()
+	policy.MaxAttempts = 50
+	policy.InitialBackoff = time.Millisecond
+
+	q := newQueue(t, db, Options{Repository: []gormet.Option{gormet.WithRetry(policy)}})
+
+	for i := 0; i < 10; i++ {
+		_, err := q.Enqueue(testEmail{})
+		assert.Nil(t, err)
+	}
+
+	var handled atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler := func(ctx context.Context, job *Job[testEmail]) error {
+		if handled.Add(1) == 10 {
+			cancel()
+		}
+
+		return nil
+	}
+
+	err := q.Work(ctx, handler, WorkerOptions{Concurrency: 3, PollInterval: 10 * time.Millisecond})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(10), handled.Load())
+
+	done, err := q.Count(StatusDone)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), done)
+}
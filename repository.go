@@ -53,25 +53,72 @@
 package gormet
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // Repository is a generic repository type that provides
 // CRUD operations for a given model that is represented by a GORM model.
 type Repository[T any] struct {
-	db       *gorm.DB // The database connection handle.
-	PageSize uint     // Define if the size of page
-	pkName   string   // The name of the primary key field in the database table.
+	db        *gorm.DB       // The database connection handle.
+	PageSize  uint           // Define if the size of page
+	pkName    string         // The name of the primary key field in the database table.
+	schema    *schema.Schema // The parsed GORM schema of the model type T.
+	config    *config        // The settings assembled from the options passed to New.
+	inTx      bool           // Whether the repository is bound to a transaction.
+	latest    string         // The column defining the recency of the entities.
+	search    *searchConfig  // The search options of the current call, nil outside a search.
+	tracker   *tracker       // The snapshots of the loaded entities, nil when the repository is not tracked.
+	unscoped  bool           // Whether the default scopes are ignored.
+	committed *[]func()      // The functions run once the enclosing transaction is committed, nil outside a transaction.
 }
 
+// RepositoryAPI is the set of operations provided by a repository for the model type T.
+//
+// It is implemented by Repository, which runs the operations against the database, and by
+// MemoryRepository, which keeps the entities in memory. Services should depend on this
+// interface so they can be unit tested with the in-memory implementation.
+type RepositoryAPI[T any] interface {
+	Get(entity T) (*T, error)
+	GetById(id interface{}) (*T, error)
+	GetLatest() (*T, error)
+	GetOldest() (*T, error)
+	GetLatestN(n uint, query interface{}, args ...interface{}) ([]T, error)
+	Create(entity *T) error
+	Update(entity *T) error
+	Delete(entity *T) error
+	DeleteById(id interface{}) error
+	UpdateWhere(query interface{}, values map[string]interface{}, args ...interface{}) (int64, error)
+	DeleteWhere(query interface{}, args ...interface{}) (int64, error)
+	Increment(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error)
+	Decrement(id interface{}, column string, delta int64, opts ...CounterOption) (int64, error)
+	Search(page uint, query interface{}, args ...interface{}) (Pagination[T], error)
+	SearchWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error)
+	SearchSlice(page uint, query interface{}, args ...interface{}) (Slice[T], error)
+	SearchSliceWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Slice[T], error)
+	SearchUnpaged(query interface{}, args ...interface{}) (Pagination[T], error)
+	SearchUnpagedWith(opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error)
+	SearchAll(query interface{}, args ...interface{}) ([]T, error)
+	SearchAllWith(opts []SearchOption, query interface{}, args ...interface{}) ([]T, error)
+	Count(query interface{}, args ...interface{}) (int64, error)
+	CountWith(opts []SearchOption, query interface{}, args ...interface{}) (int64, error)
+	FindByExample(page uint, example T, matcher ExampleMatcher, opts ...SearchOption) (Pagination[T], error)
+}
+
+// Ensure Repository implements RepositoryAPI.
+var _ RepositoryAPI[any] = (*Repository[any])(nil)
+
 // New creates and returns a new instance of Repository for a specific model type T,
 // with the provided database connection and optional configuration settings.
-// It automatically determines the primary key field for the model type T.
+// It automatically determines the primary key field for the model type T and validates
+// the options against the model schema.
 //
 // Usage:
-// repo, err := New[YourModelType](db, nil)
+// repo, err := New[YourModelType](db, WithPageSize(20), WithMaxPageSize(100))
 //
 //	if err != nil {
 //	    // Handle error
@@ -79,11 +126,12 @@ type Repository[T any] struct {
 //
 // Parameters:
 //   - db: A *gorm.DB instance representing the database connection.
+//   - opts: Optional settings such as page size, default sort, soft delete strategy, logger, cache, hooks and retry policy.
 //
 // Returns:
 // - A pointer to a newly created Repository for type T if successful.
-// - An error if there is a failure in determining the primary key or other initializations.
-func New[T any](db *gorm.DB) (*Repository[T], error) {
+// - An error if there is a failure in determining the primary key, if an option is invalid or other initializations.
+func New[T any](db *gorm.DB, opts ...Option) (*Repository[T], error) {
 	// Initialize a variable to hold the name of the primary key field.
 	var pkName string
 	var err error
@@ -100,11 +148,53 @@ func New[T any](db *gorm.DB) (*Repository[T], error) {
 		return nil, fmt.Errorf("impossible to retrieve primary key: %v", err)
 	}
 
+	// The schema was already parsed by getPrimaryKeyFieldName, so this hits the GORM cache.
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(new(T)); err != nil {
+		return nil, fmt.Errorf("data struct parse error: %s", err.Error())
+	}
+
+	// Apply the options over the default configuration and validate them against the model.
+	cfg, err := newConfig(stmt.Schema, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the table of the model, so a missing migration fails at startup.
+	if cfg.schemaCheck {
+		if err = checkSchema(db, stmt.Schema, new(T)); err != nil {
+			return nil, fmt.Errorf("schema check failed: %v", err)
+		}
+	}
+
+	// The versions are recorded by hooks, so they are written in the transaction of each write.
+	if cfg.history {
+		if cfg.temporal, err = newTemporal(stmt.Schema, pkName); err != nil {
+			return nil, fmt.Errorf("invalid option: %v", err)
+		}
+
+		cfg.hooks = append(cfg.hooks, cfg.temporal.hooks(stmt.Schema.LookUpField(pkName)))
+	}
+
+	// Every statement issued by the repository goes through the configured logger.
+	if cfg.logger != nil {
+		db = db.Session(&gorm.Session{Logger: cfg.logger})
+	}
+
 	// Create a new Repository instance for the model type T with the database connection,
 	// configuration, and primary key name.
 	repo := &Repository[T]{
-		db:     db,
-		pkName: pkName,
+		db:       db,
+		PageSize: cfg.pageSize,
+		pkName:   pkName,
+		schema:   stmt.Schema,
+		config:   cfg,
+		latest:   latestColumn(stmt.Schema, cfg.latest, pkName),
+	}
+
+	// The specifications of the scopes are converted once, as the criteria of the searches would be.
+	if err = repo.resolveScopes(); err != nil {
+		return nil, fmt.Errorf("invalid option: %v", err)
 	}
 
 	// Return the newly created repository and nil error (indicating success).
@@ -153,3 +243,20 @@ func getPrimaryKeyFieldName(db *gorm.DB, model interface{}) (string, error) {
 	// If no primary key field is found, return an error indicating so.
 	return "", fmt.Errorf("no primary key found")
 }
+
+// primaryKeyValue returns the value of the primary key of the given entity.
+func (r *Repository[T]) primaryKeyValue(entity *T) interface{} {
+	value, _ := r.schema.LookUpField(r.pkName).ValueOf(context.Background(), reflect.ValueOf(entity).Elem())
+	return value
+}
+
+// entityWithId creates a new entity of type T with only the primary key set.
+func (r *Repository[T]) entityWithId(id interface{}) (*T, error) {
+	entity := new(T)
+
+	if err := r.schema.LookUpField(r.pkName).Set(context.Background(), reflect.ValueOf(entity).Elem(), id); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,277 @@
+package gormet
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetryPolicy defines how the repository retries operations that fail with transient database
+// errors, such as SQLite's "database is locked", deadlocks or serialization failures.
+//
+// The policy is applied to the read operations, which are idempotent, and to whole transactions:
+// write operations run in a transaction when a policy is set, and are retried from the beginning
+// as are the functions passed to Transaction.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total number of attempts, including the first one.
+	InitialBackoff time.Duration // Delay before the first retry.
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts, 0 means unlimited.
+	Multiplier     float64       // Factor applied to the delay after each retry, defaults to 2.
+	Jitter         float64       // Fraction of the delay randomly removed, between 0 and 1.
+
+	// Retryable classifies the errors that can be retried. When nil, IsRetryable is used
+	// with the dialect of the database connection.
+	Retryable func(err error) bool
+
+	// OnRetry, if set, is invoked before each retry with the attempt that failed, its error
+	// and the delay before the next attempt.
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+// RetryStats holds the counters of the retries performed by a repository.
+type RetryStats struct {
+	Retries   uint64 // Number of retries performed.
+	Recovered uint64 // Number of operations that succeeded after at least one retry.
+	Exhausted uint64 // Number of operations that failed after all the attempts.
+}
+
+// retryState holds the resolved policy and the counters of a repository.
+type retryState struct {
+	policy    RetryPolicy
+	retries   atomic.Uint64
+	recovered atomic.Uint64
+	exhausted atomic.Uint64
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and an exponential backoff starting
+// at 50 milliseconds, capped at 1 second, with 20% of jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetry enables the retry of operations that fail with transient database errors.
+//
+// Usage:
+// repo, err := gormet.New[User](db, gormet.WithRetry(gormet.DefaultRetryPolicy()))
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) error {
+		if policy.MaxAttempts < 1 {
+			return errors.New("the retry max attempts should be at least 1")
+		}
+
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+			return errors.New("the retry backoff should not be negative")
+		}
+
+		if policy.Multiplier == 0 {
+			policy.Multiplier = 2
+		}
+
+		if policy.Multiplier < 1 {
+			return errors.New("the retry multiplier should be at least 1")
+		}
+
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return errors.New("the retry jitter should be between 0 and 1")
+		}
+
+		c.retry = &retryState{policy: policy}
+		return nil
+	}
+}
+
+// IsRetryable reports whether the error is a transient error for the given GORM dialect name,
+// such as "sqlite", "postgres", "mysql" or "sqlserver".
+//
+// Usage:
+// retryable := gormet.IsRetryable(db.Dialector.Name(), err)
+func IsRetryable(dialect string, err error) bool {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+
+	var patterns []string
+	switch dialect {
+	case "sqlite", "sqlite3":
+		patterns = []string{"database is locked", "database table is locked", "sqlite_busy", "sqlite_locked"}
+	case "postgres", "postgresql":
+		patterns = []string{"sqlstate 40001", "sqlstate 40p01", "could not serialize access", "deadlock detected"}
+	case "mysql":
+		patterns = []string{"error 1213", "error 1205", "deadlock found", "lock wait timeout exceeded"}
+	case "sqlserver":
+		patterns = []string{"error 1205", "deadlock victim", "error 1222", "lock request time out"}
+	default:
+		patterns = []string{"deadlock", "database is locked", "could not serialize access"}
+	}
+
+	for _, pattern := range patterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryStats returns the counters of the retries performed by the repository.
+// All the counters are zero when the repository is not configured WithRetry.
+func (r *Repository[T]) RetryStats() RetryStats {
+	if r.config.retry == nil {
+		return RetryStats{}
+	}
+
+	return RetryStats{
+		Retries:   r.config.retry.retries.Load(),
+		Recovered: r.config.retry.recovered.Load(),
+		Exhausted: r.config.retry.exhausted.Load(),
+	}
+}
+
+// Transaction runs the function within a database transaction, passing a repository bound to it.
+// The transaction is committed if the function returns nil and rolled back otherwise. When the
+// repository is configured WithRetry, the whole transaction is retried on transient errors, so the
+// function must not have side effects outside the database. The cached entities written through the
+// bound repository are invalidated once the transaction is committed.
+//
+// Usage:
+//
+//	err := repo.Transaction(func(tx *gormet.Repository[User]) error {
+//		user, err := tx.GetById(1)
+//		if err != nil {
+//			return err
+//		}
+//
+//		user.Name = "new name"
+//		return tx.Update(user)
+//	})
+//
+// Parameters:
+// - fn: The function to run within the transaction.
+//
+// Returns:
+// - nil if the transaction is committed.
+// - The error returned by the function, or by the database when starting or committing the transaction.
+func (r *Repository[T]) Transaction(fn func(tx *Repository[T]) error) error {
+	// The functions run once the transaction is committed, collected again by each attempt.
+	var committed []func()
+
+	err := r.transaction(func(tx *gorm.DB) error {
+		committed = nil
+
+		repo := r.withTx(tx)
+		repo.committed = &committed
+
+		return fn(repo)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.afterCommit(committed...)
+	return nil
+}
+
+// transaction runs the function within a database transaction, retried as a whole according to
+// the retry policy.
+func (r *Repository[T]) transaction(fn func(tx *gorm.DB) error) error {
+	return r.retry(func() error {
+		return r.db.Transaction(fn)
+	})
+}
+
+// withTx returns a copy of the repository bound to the given transaction.
+func (r *Repository[T]) withTx(tx *gorm.DB) *Repository[T] {
+	repo := *r
+	repo.db = tx
+	repo.inTx = true
+
+	return &repo
+}
+
+// afterCommit runs the functions once the enclosing transaction is committed, or right away when the
+// repository is not bound to a transaction collecting them.
+func (r *Repository[T]) afterCommit(fns ...func()) {
+	if r.committed != nil {
+		*r.committed = append(*r.committed, fns...)
+		return
+	}
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// retry runs the operation, retrying it according to the configured policy. Operations running
+// inside a transaction are not retried on their own, since the whole transaction must be retried.
+func (r *Repository[T]) retry(op func() error) error {
+	state := r.config.retry
+	if state == nil || r.inTx {
+		return op()
+	}
+
+	retryable := state.policy.Retryable
+	if retryable == nil {
+		dialect := r.db.Dialector.Name()
+		retryable = func(err error) bool { return IsRetryable(dialect, err) }
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+
+		if err == nil {
+			if attempt > 1 {
+				state.recovered.Add(1)
+			}
+
+			return nil
+		}
+
+		if !retryable(err) {
+			return err
+		}
+
+		if attempt >= state.policy.MaxAttempts {
+			state.exhausted.Add(1)
+			return err
+		}
+
+		delay := state.policy.backoff(attempt)
+
+		if state.policy.OnRetry != nil {
+			state.policy.OnRetry(attempt, err, delay)
+		}
+
+		state.retries.Add(1)
+		time.Sleep(delay)
+	}
+}
+
+// backoff returns the delay after the given failed attempt, applying the exponential growth,
+// the upper bound and the jitter.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,200 @@
+package gormet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testRetry struct {
+	gorm.Model
+	Name string `json:"name" gorm:"unique;not null;default:null"`
+}
+
+type testRetryLog struct {
+	ID      uint   `json:"id"`
+	Message string `json:"message"`
+}
+
+// failingHooks returns hooks whose BeforeCreate fails with a transient error for the given number of calls.
+func failingHooks(times int) Hooks {
+	calls := 0
+
+	return Hooks{
+		BeforeCreate: func(tx *gorm.DB, entity interface{}) error {
+			calls++
+			if calls <= times {
+				return errors.New("database is locked")
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestRepository_Retry(t *testing.T) {
+	db := getGormConnection(t, &testRetry{})
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	t.Run("Recovered after retries", func(t *testing.T) {
+		var attempts []int
+		policy := policy
+		policy.OnRetry = func(attempt int, err error, delay time.Duration) {
+			attempts = append(attempts, attempt)
+		}
+
+		repo, err := New[testRetry](db, WithRetry(policy), WithHooks(failingHooks(2)))
+		assert.Nil(t, err)
+
+		entity := &testRetry{Name: uuid.NewString()}
+		assert.Nil(t, repo.Create(entity))
+		assert.NotZero(t, entity.ID)
+
+		assert.Equal(t, []int{1, 2}, attempts)
+		assert.Equal(t, RetryStats{Retries: 2, Recovered: 1}, repo.RetryStats())
+	})
+
+	t.Run("Exhausted attempts", func(t *testing.T) {
+		repo, err := New[testRetry](db, WithRetry(policy), WithHooks(failingHooks(5)))
+		assert.Nil(t, err)
+
+		err = repo.Create(&testRetry{Name: uuid.NewString()})
+		assert.Equal(t, "database is locked", err.Error())
+		assert.Equal(t, RetryStats{Retries: 2, Exhausted: 1}, repo.RetryStats())
+	})
+
+	t.Run("Non retryable error", func(t *testing.T) {
+		repo, err := New[testRetry](db, WithRetry(policy))
+		assert.Nil(t, err)
+
+		_, err = repo.GetById(0)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Equal(t, RetryStats{}, repo.RetryStats())
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		_, err := New[testRetry](db, WithRetry(RetryPolicy{}))
+		assert.NotNil(t, err)
+
+		_, err = New[testRetry](db, WithRetry(RetryPolicy{MaxAttempts: 2, Jitter: 2}))
+		assert.NotNil(t, err)
+
+		_, err = New[testRetry](db, WithRetry(RetryPolicy{MaxAttempts: 2, Multiplier: 0.5}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_RetryWrite(t *testing.T) {
+	// A dedicated connection, so the failing callback doesn't leak into the other tests.
+	db := getGormConnection(t, &testRetryLog{})
+
+	calls := 0
+	err := db.Callback().Create().After("gorm:create").Register("test:fail_once", func(tx *gorm.DB) {
+		if calls++; calls == 1 {
+			_ = tx.AddError(errors.New("database is locked"))
+		}
+	})
+	assert.Nil(t, err)
+
+	repo, err := New[testRetryLog](db, WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+	assert.Nil(t, err)
+
+	message := uuid.NewString()
+	assert.Nil(t, repo.Create(&testRetryLog{Message: message}))
+
+	// The failed insert was rolled back with its transaction, so the retry doesn't duplicate it.
+	count, err := repo.Count("message = ?", message)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, RetryStats{Retries: 1, Recovered: 1}, repo.RetryStats())
+}
+
+func TestRepository_Transaction(t *testing.T) {
+	db := getGormConnection(t, &testRetry{})
+
+	repo, err := New[testRetry](db, WithRetry(DefaultRetryPolicy()))
+	assert.Nil(t, err)
+
+	t.Run("Commit", func(t *testing.T) {
+		entity := &testRetry{Name: uuid.NewString()}
+
+		err := repo.Transaction(func(tx *Repository[testRetry]) error {
+			assert.True(t, tx.inTx)
+			return tx.Create(entity)
+		})
+		assert.Nil(t, err)
+
+		got, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.Name, got.Name)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		entity := &testRetry{Name: uuid.NewString()}
+
+		err := repo.Transaction(func(tx *Repository[testRetry]) error {
+			if err := tx.Create(entity); err != nil {
+				return err
+			}
+
+			return errors.New("abort")
+		})
+		assert.Equal(t, "abort", err.Error())
+
+		_, err = repo.Get(testRetry{Name: entity.Name})
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("Whole transaction is retried", func(t *testing.T) {
+		calls := 0
+
+		err := repo.Transaction(func(tx *Repository[testRetry]) error {
+			calls++
+			if calls == 1 {
+				return errors.New("database is locked")
+			}
+
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		dialect string
+		err     error
+		want    bool
+	}{
+		{dialect: "sqlite", err: errors.New("database is locked"), want: true},
+		{dialect: "sqlite", err: errors.New("UNIQUE constraint failed"), want: false},
+		{dialect: "postgres", err: errors.New("ERROR: could not serialize access (SQLSTATE 40001)"), want: true},
+		{dialect: "postgres", err: errors.New("ERROR: deadlock detected (SQLSTATE 40P01)"), want: true},
+		{dialect: "mysql", err: errors.New("Error 1213: Deadlock found when trying to get lock"), want: true},
+		{dialect: "mysql", err: errors.New("Error 1062: Duplicate entry"), want: false},
+		{dialect: "sqlite", err: gorm.ErrRecordNotFound, want: false},
+		{dialect: "sqlite", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsRetryable(tt.dialect, tt.err), "%s: %v", tt.dialect, tt.err)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond, Multiplier: 2}
+
+	assert.Equal(t, 10*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 20*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 35*time.Millisecond, policy.backoff(3))
+
+	policy.Jitter = 0.5
+	delay := policy.backoff(1)
+	assert.True(t, delay > 5*time.Millisecond && delay <= 10*time.Millisecond)
+}
@@ -0,0 +1,210 @@
+package gormet
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// scope is a criteria registered by WithDefaultScope or WithNamedScope.
+type scope struct {
+	query interface{}
+	args  []interface{}
+}
+
+// Unscoped returns a copy of the repository ignoring the default scopes, for instance to retrieve
+// the archived entities hidden by them. Soft deleted entities stay hidden, as in GORM.
+//
+// Usage:
+//
+//	// Retrieve an archived post
+//	post, err := postRepo.Unscoped().GetById(postId)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Returns:
+// - A copy of the repository without default scopes.
+func (r *Repository[T]) Unscoped() *Repository[T] {
+	repo := *r
+	repo.unscoped = true
+
+	return &repo
+}
+
+// Scope returns a search option applying the named scopes registered WithNamedScope to the search,
+// in addition to its criteria and to the default scopes.
+//
+// Usage:
+//
+//	resp, err := postRepo.Search(1, "author_id = ?", authorId, postRepo.Scope("published", "featured"))
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - names: The names of the scopes.
+//
+// Returns:
+// - The search option, failing the search if a name is not registered.
+func (r *Repository[T]) Scope(names ...string) SearchOption {
+	return scopeOption(r.config, names)
+}
+
+// scoped applies the default scopes, unless the repository is unscoped, and the named scopes of the search.
+func (r *Repository[T]) scoped(tx *gorm.DB) *gorm.DB {
+	if !r.unscoped {
+		for _, s := range r.config.defaultScopes {
+			tx = tx.Where(s.query, s.args...)
+		}
+	}
+
+	if r.search != nil {
+		for _, name := range r.search.scopes {
+			s := r.config.namedScopes[name]
+			tx = tx.Where(s.query, s.args...)
+		}
+	}
+
+	return tx
+}
+
+// resolveScopes converts the specifications of the scopes into expressions, once for all, so an
+// invalid specification is reported by New.
+func (r *Repository[T]) resolveScopes() error {
+	resolve := func(s scope) (scope, error) {
+		query, args, err := r.criteria(s.query, s.args)
+		return scope{query: query, args: args}, err
+	}
+
+	for i, s := range r.config.defaultScopes {
+		resolved, err := resolve(s)
+		if err != nil {
+			return fmt.Errorf("invalid default scope: %v", err)
+		}
+
+		r.config.defaultScopes[i] = resolved
+	}
+
+	for name, s := range r.config.namedScopes {
+		resolved, err := resolve(s)
+		if err != nil {
+			return fmt.Errorf("invalid scope %q: %v", name, err)
+		}
+
+		r.config.namedScopes[name] = resolved
+	}
+
+	return nil
+}
+
+// scopeKey describes the scopes applied to a search, to distinguish its cached count.
+func (r *Repository[T]) scopeKey() string {
+	var names []string
+
+	if r.search != nil {
+		names = r.search.scopes
+	}
+
+	return fmt.Sprintf("%t:%v", r.unscoped, names)
+}
+
+// Unscoped returns a copy of the repository ignoring the default scopes.
+func (m *MemoryRepository[T]) Unscoped() *MemoryRepository[T] {
+	repo := *m
+	repo.unscoped = true
+
+	return &repo
+}
+
+// Scope returns a search option applying the named scopes registered WithNamedScope to the search.
+func (m *MemoryRepository[T]) Scope(names ...string) SearchOption {
+	return scopeOption(m.config, names)
+}
+
+// inScope reports whether the entity matches the default scopes, unless the repository is unscoped,
+// and the named scopes of the search.
+func (m *MemoryRepository[T]) inScope(entity reflect.Value) bool {
+	if !m.unscoped {
+		for _, match := range m.scopes.defaults {
+			if !match(entity) {
+				return false
+			}
+		}
+	}
+
+	if m.search != nil {
+		for _, name := range m.search.scopes {
+			if !m.scopes.named[name](entity) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// memoryScopes holds the predicates of the scopes of a MemoryRepository.
+type memoryScopes struct {
+	defaults []predicate
+	named    map[string]predicate
+}
+
+// newMemoryScopes parses the scopes of the configuration into predicates.
+func newMemoryScopes(m *memoryScopes, cfg *config, parse func(s scope) (predicate, error)) error {
+	for _, s := range cfg.defaultScopes {
+		match, err := parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid default scope: %v", err)
+		}
+
+		m.defaults = append(m.defaults, match)
+	}
+
+	m.named = make(map[string]predicate, len(cfg.namedScopes))
+
+	for name, s := range cfg.namedScopes {
+		match, err := parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid scope %q: %v", name, err)
+		}
+
+		m.named[name] = match
+	}
+
+	return nil
+}
+
+// scopeOption builds the search option applying the named scopes of the configuration.
+func scopeOption(cfg *config, names []string) SearchOption {
+	return func(c *searchConfig) error {
+		if len(names) == 0 {
+			return fmt.Errorf("the scope names should not be empty")
+		}
+
+		for _, name := range names {
+			if _, ok := cfg.namedScopes[name]; !ok {
+				return fmt.Errorf("unknown scope: %q", name)
+			}
+
+			c.scopes = append(c.scopes, name)
+		}
+
+		return nil
+	}
+}
+
+// isBlankScope reports whether the criteria of a scope is missing.
+func isBlankScope(query interface{}) bool {
+	if query == nil {
+		return true
+	}
+
+	if s, ok := query.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+
+	return false
+}
@@ -0,0 +1,309 @@
+package gormet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testScopePost struct {
+	gorm.Model
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Featured bool   `json:"featured"`
+}
+
+func titles(posts []testScopePost) []string {
+	result := make([]string, len(posts))
+	for i, post := range posts {
+		result[i] = post.Title
+	}
+
+	return result
+}
+
+func TestScope(t *testing.T) {
+	db := getGormConnection(t, &testScopePost{})
+	assert.Nil(t, db.Exec("DELETE FROM test_scope_posts").Error)
+
+	repo, err := New[testScopePost](db,
+		WithDefaultScope(Not(Eq[testScopePost]("status", "archived"))),
+		WithNamedScope("published", "status = ?", "published"),
+		WithNamedScope("featured", "featured = ?", true),
+	)
+	assert.Nil(t, err)
+
+	draft := &testScopePost{Title: "draft", Status: "draft"}
+	published := &testScopePost{Title: "published", Status: "published"}
+	featured := &testScopePost{Title: "featured", Status: "published", Featured: true}
+	archived := &testScopePost{Title: "archived", Status: "archived", Featured: true}
+
+	for _, post := range []*testScopePost{draft, published, featured, archived} {
+		assert.Nil(t, repo.Create(post))
+	}
+
+	t.Run("Default scope", func(t *testing.T) {
+		_, err := repo.GetById(archived.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repo.Get(testScopePost{Title: "archived"})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		latest, err := repo.GetLatest()
+		assert.Nil(t, err)
+		assert.Equal(t, "featured", latest.Title)
+
+		all, err := repo.SearchAll(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"draft", "published", "featured"}, titles(all))
+
+		page, err := repo.Search(1, "featured = ?", true)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), page.Response.TotalCount)
+		assert.Equal(t, []string{"featured"}, titles(page.Response.Entities))
+
+		count, err := repo.Count(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Unscoped", func(t *testing.T) {
+		post, err := repo.Unscoped().GetById(archived.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "archived", post.Title)
+
+		count, err := repo.Unscoped().Count(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), count)
+
+		// The copy leaves the repository scoped.
+		count, err = repo.Count(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Named scopes", func(t *testing.T) {
+		all, err := repo.SearchAll(nil, repo.Scope("published"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"published", "featured"}, titles(all))
+
+		all, err = repo.SearchAll(nil, repo.Scope("published", "featured"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"featured"}, titles(all))
+
+		page, err := repo.Search(1, "title <> ?", "featured", repo.Scope("published"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), page.Response.TotalCount)
+		assert.Equal(t, []string{"published"}, titles(page.Response.Entities))
+
+		count, err := repo.Count(nil, repo.Scope("featured"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repo.Unscoped().Count(nil, repo.Scope("featured"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		_, err := repo.SearchAll(nil, repo.Scope("deleted"))
+		assert.EqualError(t, err, `invalid search option: unknown scope: "deleted"`)
+
+		_, err = repo.Count(nil, repo.Scope())
+		assert.EqualError(t, err, "invalid search option: the scope names should not be empty")
+	})
+
+	t.Run("Writes are not scoped", func(t *testing.T) {
+		archived.Title = "archived post"
+		assert.Nil(t, repo.Update(archived))
+
+		post, err := repo.Unscoped().GetById(archived.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "archived post", post.Title)
+	})
+}
+
+type testScopeNode struct {
+	gorm.Model
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId"`
+	Archived bool   `json:"archived"`
+}
+
+func TestScopeTree(t *testing.T) {
+	db := getGormConnection(t, &testScopeNode{})
+	assert.Nil(t, db.Exec("DELETE FROM test_scope_nodes").Error)
+
+	repo, err := New[testScopeNode](db,
+		WithTree("parent_id"),
+		WithDefaultScope(Eq[testScopeNode]("archived", false)),
+		WithDefaultScope("name <> ?", "hidden"),
+	)
+	assert.Nil(t, err)
+
+	create := func(name string, parent *testScopeNode, archived bool) *testScopeNode {
+		node := &testScopeNode{Name: name, Archived: archived}
+		if parent != nil {
+			node.ParentID = &parent.ID
+		}
+
+		assert.Nil(t, repo.Create(node))
+		return node
+	}
+
+	// root
+	// ├── a
+	// │   ├── a1
+	// │   └── hidden
+	// └── b (archived)
+	//     └── b1
+	root := create("root", nil, false)
+	a := create("a", root, false)
+	a1 := create("a1", a, false)
+	create("hidden", a, false)
+	b := create("b", root, true)
+	b1 := create("b1", b, false)
+
+	names := func(nodes []testScopeNode) []string {
+		result := make([]string, len(nodes))
+		for i, node := range nodes {
+			result[i] = node.Name
+		}
+
+		return result
+	}
+
+	t.Run("Descendants", func(t *testing.T) {
+		descendants, err := repo.Descendants(root.ID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "a1"}, names(descendants))
+
+		children, err := repo.Children(root.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, names(children))
+
+		descendants, err = repo.Unscoped().Descendants(root.ID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "a1", "hidden", "b1"}, names(descendants))
+	})
+
+	t.Run("Ancestors", func(t *testing.T) {
+		ancestors, err := repo.Ancestors(a1.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "root"}, names(ancestors))
+	})
+
+	t.Run("Tree and forest", func(t *testing.T) {
+		tree, err := repo.Tree(root.ID, 0)
+		assert.Nil(t, err)
+		assert.Len(t, tree.Children, 1)
+		assert.Equal(t, "a", tree.Children[0].Entity.Name)
+		assert.Len(t, tree.Children[0].Children, 1)
+
+		forest, err := repo.Forest(0)
+		assert.Nil(t, err)
+		assert.Len(t, forest, 1)
+		assert.Len(t, forest[0].Children, 1)
+
+		forest, err = repo.Unscoped().Forest(0)
+		assert.Nil(t, err)
+		assert.Len(t, forest[0].Children, 2)
+
+		_, err = repo.Tree(b.ID, 0)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Move across a hidden ancestor", func(t *testing.T) {
+		// b1 descends from root through b, which the scopes hide.
+		err := repo.Move(root.ID, b1.ID)
+		assert.Equal(t, fmt.Sprintf("moving %d under %d would create a cycle", root.ID, b1.ID), err.Error())
+
+		stored, err := repo.GetById(root.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, stored.ParentID)
+	})
+}
+
+func TestScopeCache(t *testing.T) {
+	db := getGormConnection(t, &testScopePost{})
+	assert.Nil(t, db.Exec("DELETE FROM test_scope_posts").Error)
+
+	repo, err := New[testScopePost](db,
+		WithCache(NewMemoryCache(), 0),
+		WithDefaultScope("status <> ?", "archived"),
+	)
+	assert.Nil(t, err)
+
+	post := &testScopePost{Title: "archived", Status: "archived"}
+	assert.Nil(t, repo.Create(post))
+
+	_, err = repo.Unscoped().GetById(post.ID)
+	assert.Nil(t, err)
+
+	// The entity loaded by the unscoped copy is not cached for the scoped repository.
+	_, err = repo.GetById(post.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestScopeOptions(t *testing.T) {
+	db := getGormConnection(t, &testScopePost{})
+
+	t.Run("Empty default scope", func(t *testing.T) {
+		_, err := New[testScopePost](db, WithDefaultScope(" "))
+		assert.EqualError(t, err, "invalid option: the default scope should not be empty")
+	})
+
+	t.Run("Invalid named scope", func(t *testing.T) {
+		_, err := New[testScopePost](db, WithNamedScope("", "featured = ?", true))
+		assert.EqualError(t, err, "invalid option: the scope name should not be empty")
+
+		_, err = New[testScopePost](db, WithNamedScope("featured", nil))
+		assert.EqualError(t, err, `invalid option: the scope "featured" should not be empty`)
+
+		_, err = New[testScopePost](db,
+			WithNamedScope("featured", "featured = ?", true),
+			WithNamedScope("featured", "featured = ?", false))
+		assert.EqualError(t, err, `invalid option: the scope "featured" is already registered`)
+	})
+
+	t.Run("Invalid specification", func(t *testing.T) {
+		_, err := New[testScopePost](db, WithNamedScope("unknown", Eq[testScopePost]("unknown", 1)))
+		assert.ErrorContains(t, err, `invalid option: invalid scope "unknown"`)
+	})
+}
+
+func TestMemoryScope(t *testing.T) {
+	repo, err := NewMemory[testScopePost](
+		WithDefaultScope("status <> ?", "archived"),
+		WithNamedScope("featured", "featured = ?", true),
+	)
+	assert.Nil(t, err)
+
+	for _, post := range []*testScopePost{
+		{Title: "draft", Status: "draft"},
+		{Title: "featured", Status: "published", Featured: true},
+		{Title: "archived", Status: "archived", Featured: true},
+	} {
+		assert.Nil(t, repo.Create(post))
+	}
+
+	all, err := repo.SearchAll(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"draft", "featured"}, titles(all))
+
+	all, err = repo.SearchAll(nil, repo.Scope("featured"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"featured"}, titles(all))
+
+	count, err := repo.Unscoped().Count(nil, repo.Scope("featured"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = repo.Get(testScopePost{Title: "archived"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repo.SearchAll(nil, repo.Scope("deleted"))
+	assert.EqualError(t, err, `invalid search option: unknown scope: "deleted"`)
+}
@@ -24,10 +24,14 @@ type Response[T any] struct {
 // and returns the paginated results. The paginated results include the entities found and additional
 // information such as total count and pagination details.
 //
+// Search options passed after the arguments, such as SearchPageSize, SearchSort, SearchSelect or
+// SearchPreload, customize the call without changing the repository, so it can be shared by concurrent goroutines.
+//
 // Usage:
 // query := "your_column = ?"
 // args := []interface{}{"your_value"}
 // pagination, err := repo.Search(page, query, args...)
+// pagination, err = repo.Search(page, query, "your_value", gormet.SearchPageSize(50))
 //
 //	if err != nil {
 //	    // Handle error
@@ -38,38 +42,55 @@ type Response[T any] struct {
 // Parameters:
 // - page: The page number for pagination (starting from 1).
 // - query: GORM query condition.
-// - args: Arguments for the query condition.
+// - args: Arguments for the query condition, optionally followed by search options such as SearchSort.
 //
 // Returns:
 // - A structure containing the paginated search results, including entities, total count, and pagination details.
 // - An error if the search operation encounters any issues.
 func (r *Repository[T]) Search(page uint, query interface{}, args ...interface{}) (Pagination[T], error) {
-	var offset int = getOffset(page, r.PageSize)
-	var limit int = getLimit(r.PageSize)
-
-	var entities []T
-	var count int64
-	var err error
+	return r.SearchWith(page, nil, query, args...)
+}
 
-	if entities, err = r.executeSearch(offset, limit, query, args...); err != nil {
+// SearchWith performs a paginated search as Search, customized by the given search options. The
+// options are typed, so they are checked at compile time and never mixed with the arguments of the
+// query condition.
+//
+// Usage:
+//
+//	opts := []gormet.SearchOption{gormet.SearchPageSize(50), gormet.SearchSort("name ASC")}
+//
+//	pagination, err := repo.SearchWith(page, opts, "your_column = ?", "your_value")
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - page: The page number for pagination (starting from 1).
+// - opts: The search options, such as SearchPageSize or SearchSort.
+// - query: GORM query condition or Specification.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - A structure containing the paginated search results, including entities, total count, and pagination details.
+// - An error if an option is invalid or if the search operation encounters any issues.
+func (r *Repository[T]) SearchWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error) {
+	repo, args, err := r.withSearchOptions(args, opts...)
+	if err != nil {
 		return Pagination[T]{}, err
 	}
 
-	if count, err = r.countRows(query, args...); err != nil {
+	var pageSize uint = repo.pageSize()
+	var offset int = getOffset(page, pageSize)
+	var limit int = getLimit(pageSize)
+
+	entities, count, err := repo.searchPage(offset, limit, query, args...)
+	if err != nil {
 		return Pagination[T]{}, err
 	}
 
 	// Create a Pagination with the initial response, criteria, and repository.
 	pagination := Pagination[T]{
-		Response: Response[T]{
-			Entities:    entities,
-			TotalCount:  count,
-			Page:        page,
-			PageSize:    r.PageSize,
-			TotalPages:  countTotalPages(count, limit, r.PageSize),
-			HasNextPage: getHasNextPage(page, limit, count),
-			HasPrevPage: getHasPreviousPage(page),
-		},
+		Response:   newResponse(entities, count, page, pageSize),
 		criteria:   query,
 		repository: r,
 	}
@@ -77,27 +98,94 @@ func (r *Repository[T]) Search(page uint, query interface{}, args ...interface{}
 	return pagination, nil
 }
 
+// newResponse builds the paginated response for the given page of entities and total count.
+func newResponse[T any](entities []T, count int64, page uint, pageSize uint) Response[T] {
+	limit := getLimit(pageSize)
+
+	return Response[T]{
+		Entities:    entities,
+		TotalCount:  count,
+		Page:        page,
+		PageSize:    pageSize,
+		TotalPages:  countTotalPages(count, limit, pageSize),
+		HasNextPage: getHasNextPage(page, limit, count),
+		HasPrevPage: getHasPreviousPage(page),
+	}
+}
+
+// pageSize returns the page size used by searches, clamped to the configured maximum.
+func (r *Repository[T]) pageSize() uint {
+	return r.config.searchPageSize(r.search, r.PageSize)
+}
+
 // executeSearch performs the paginated search using GORM's Find method.
 func (r *Repository[T]) executeSearch(offset int, limit int, query interface{}, args ...interface{}) ([]T, error) {
 	entities := make([]T, 0)
-	searchResult := r.db.Debug().Where(query, args...).Offset(offset).Limit(limit).Find(&entities)
 
-	return entities, searchResult.Error
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return entities, err
+	}
+
+	err = r.retry(func() error {
+		entities = entities[:0]
+		tx := r.scoped(r.db).Where(query, args...)
+
+		if sort := r.config.searchSort(r.search); sort != "" {
+			tx = tx.Order(sort)
+		}
+
+		if r.search != nil {
+			if len(r.search.selects) > 0 {
+				tx = tx.Select(r.search.selects)
+			}
+
+			for _, association := range r.search.preloads {
+				tx = tx.Preload(association)
+			}
+
+			if r.search.lock != nil {
+				locking, err := r.lockClause(r.search.lock)
+				if err != nil {
+					return err
+				}
+
+				tx = tx.Clauses(locking)
+			}
+		}
+
+		return tx.Offset(offset).Limit(limit).Find(&entities).Error
+	})
+
+	if err == nil {
+		r.trackAll(entities)
+	}
+
+	return entities, err
 }
 
 // countRows gets the total count for the entire search without pagination.
 func (r *Repository[T]) countRows(query interface{}, args ...interface{}) (int64, error) {
 	var totalCount int64
-	result := r.db.Model(new(T)).Where(query, args...).Count(&totalCount)
 
-	return totalCount, result.Error
+	query, args, err := r.criteria(query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.retry(func() error {
+		return r.scoped(r.db.Model(new(T))).Where(query, args...).Count(&totalCount).Error
+	})
+
+	return totalCount, err
 }
 
 // SearchAll performs a paginated search for all entities in the database based on given criteria.
 //
 // This method takes a GORM query condition, performs a paginated search using GORM's Find method,
 // and returns the paginated results. The paginated results include all entities found and additional
-// information such as total count and pagination details.
+// information such as total count and pagination details. The max page size of the repository does
+// not apply.
 //
 // Usage:
 // query := "your_column = ?"
@@ -112,16 +200,38 @@ func (r *Repository[T]) countRows(query interface{}, args ...interface{}) (int64
 //
 // Parameters:
 // - query: GORM query condition.
-// - args: Arguments for the query condition.
+// - args: Arguments for the query condition, optionally followed by search options such as SearchSort.
 //
 // Returns:
 // - A structure containing the paginated search results, including entities, total count, and pagination details.
 // - An error if the search operation encounters any issues.
 func (r *Repository[T]) SearchAll(query interface{}, args ...interface{}) ([]T, error) {
+	return r.SearchAllWith(nil, query, args...)
+}
+
+// SearchAllWith retrieves all the entities matching the given criteria as SearchAll, customized by
+// the given search options.
+//
+// Usage:
+// entities, err := repo.SearchAllWith([]gormet.SearchOption{gormet.SearchSort("name ASC")}, "active = ?", true)
+//
+// Parameters:
+// - opts: The search options, such as SearchSort or SearchPreload.
+// - query: GORM query condition or Specification.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - The matching entities.
+// - An error if an option is invalid or if the search operation encounters any issues.
+func (r *Repository[T]) SearchAllWith(opts []SearchOption, query interface{}, args ...interface{}) ([]T, error) {
+	repo, args, err := r.withSearchOptions(args, opts...)
+	if err != nil {
+		return []T{}, err
+	}
+
 	var entities []T
-	var err error
 
-	if entities, err = r.executeSearch(-1, -1, query, args...); err != nil {
+	if entities, err = repo.executeSearch(-1, -1, query, args...); err != nil {
 		return []T{}, err
 	}
 
@@ -147,12 +257,25 @@ func getLimit(pageSize uint) int {
 }
 
 // countTotalPages calculates the total number of pages based on total count, limit, and page size.
+// Without pagination (page size 0) all entities fit in a single page.
 func countTotalPages(totalCount int64, limit int, pageSize uint) int64 {
+	if pageSize == 0 {
+		if totalCount > 0 {
+			return 1
+		}
+
+		return 0
+	}
+
 	return (totalCount + int64(limit-1)) / int64(pageSize)
 }
 
 // getHasNextPage checks if there is a next page based on the current page, limit, and total count.
 func getHasNextPage(page uint, limit int, totalCount int64) bool {
+	if limit <= 0 {
+		return false
+	}
+
 	return int64(int(page)*limit) < totalCount
 }
 
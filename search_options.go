@@ -0,0 +1,237 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// SearchOption customizes a single call to Search, SearchSlice, SearchUnpaged, SearchAll or Count.
+//
+// Search options are passed to SearchWith, SearchSliceWith, SearchUnpagedWith, SearchAllWith or CountWith,
+// which check their type at compile time:
+//
+//	opts := []gormet.SearchOption{gormet.SearchPageSize(50), gormet.SearchSort("name ASC")}
+//	resp, err := repo.SearchWith(1, opts, "name LIKE ?", "jo%")
+//
+// They can also be passed after the arguments of the query condition, from which they are removed
+// before the query is built, so they never reach the database:
+//
+//	resp, err := repo.Search(1, "name LIKE ?", "jo%", gormet.SearchPageSize(50), gormet.SearchSort("name ASC"))
+//
+// They apply to the call only and never change the repository, which makes them the safe way to use
+// different page sizes or orders from concurrent goroutines, unlike assigning Repository.PageSize.
+type SearchOption func(*searchConfig) error
+
+// searchConfig holds the settings assembled from the search options of a call.
+type searchConfig struct {
+	pageSize *uint       // Page size overriding the one of the repository, nil to keep it.
+	sort     string      // Order clause overriding the default sort.
+	selects  []string    // Columns loaded, all of them when empty.
+	preloads []string    // Associations preloaded.
+	lock     *lockConfig // Row lock taken on the entities found, nil for none.
+	scopes   []string    // Named scopes applied in addition to the criteria.
+}
+
+// SearchPageSize overrides the page size of the repository for the call. The size is clamped to the
+// max page size of the repository, and a size of 0 disables the pagination when no maximum is set.
+//
+// Usage:
+// resp, err := repo.Search(1, "active = ?", true, gormet.SearchPageSize(50))
+func SearchPageSize(size uint) SearchOption {
+	return func(c *searchConfig) error {
+		c.pageSize = &size
+		return nil
+	}
+}
+
+// SearchSort overrides the default sort for the call, such as "name ASC, id DESC".
+// Each column is validated against the model schema.
+//
+// Usage:
+// resp, err := repo.Search(1, "active = ?", true, gormet.SearchSort("name ASC"))
+func SearchSort(sort string) SearchOption {
+	return func(c *searchConfig) error {
+		if strings.TrimSpace(sort) == "" {
+			return errors.New("the sort should not be empty")
+		}
+
+		c.sort = sort
+		return nil
+	}
+}
+
+// SearchSelect restricts the columns loaded for the call; the other fields are left to their zero value.
+// Each column is validated against the model schema. The option can be passed more than once.
+//
+// Usage:
+// resp, err := repo.Search(1, "active = ?", true, gormet.SearchSelect("id", "name"))
+func SearchSelect(columns ...string) SearchOption {
+	return func(c *searchConfig) error {
+		if len(columns) == 0 {
+			return errors.New("the select should define at least one column")
+		}
+
+		c.selects = append(c.selects, columns...)
+		return nil
+	}
+}
+
+// SearchPreload loads the given associations of the entities found, such as "Orders" or "Orders.Items".
+// Each association is validated against the model schema. The option can be passed more than once.
+//
+// Usage:
+// resp, err := repo.Search(1, "active = ?", true, gormet.SearchPreload("Orders"))
+func SearchPreload(associations ...string) SearchOption {
+	return func(c *searchConfig) error {
+		if len(associations) == 0 {
+			return errors.New("the preload should define at least one association")
+		}
+
+		c.preloads = append(c.preloads, associations...)
+		return nil
+	}
+}
+
+// splitSearchOptions separates the search options from the arguments of the query condition,
+// applies them after the given options and validates the result against the model schema. The
+// returned config is nil when no search option is passed.
+func splitSearchOptions(sch *schema.Schema, args []interface{}, opts ...SearchOption) ([]interface{}, *searchConfig, error) {
+	rest := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		if opt, ok := arg.(SearchOption); ok {
+			opts = append(opts, opt)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(opts) == 0 {
+		return args, nil, nil
+	}
+
+	sc := &searchConfig{}
+
+	for _, opt := range opts {
+		if opt == nil {
+			return nil, nil, errors.New("invalid search option: the option should not be nil")
+		}
+
+		if err := opt(sc); err != nil {
+			return nil, nil, fmt.Errorf("invalid search option: %v", err)
+		}
+	}
+
+	if err := sc.validate(sch); err != nil {
+		return nil, nil, fmt.Errorf("invalid search option: %v", err)
+	}
+
+	return rest, sc, nil
+}
+
+// validate checks the sort, select and preload of the search against the model schema.
+func (c *searchConfig) validate(sch *schema.Schema) error {
+	if c.sort != "" {
+		if err := validateSort(sch, c.sort); err != nil {
+			return err
+		}
+	}
+
+	for i, column := range c.selects {
+		field := lookUpDBField(sch, strings.TrimSpace(column))
+		if field == nil {
+			return fmt.Errorf("unknown select column: %q", column)
+		}
+
+		c.selects[i] = field.DBName
+	}
+
+	for _, association := range c.preloads {
+		if err := validatePreload(sch, association); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePreload checks that each step of a dotted association path is a relationship of the schema.
+func validatePreload(sch *schema.Schema, association string) error {
+	current := sch
+
+	for _, name := range strings.Split(association, ".") {
+		relationship, ok := current.Relationships.Relations[name]
+		if !ok {
+			return fmt.Errorf("unknown preload association: %q", association)
+		}
+
+		current = relationship.FieldSchema
+	}
+
+	return nil
+}
+
+// clampPageSize returns the page size clamped to the maximum, 0 meaning unlimited for both.
+func clampPageSize(size uint, max uint) uint {
+	if max > 0 && (size == 0 || size > max) {
+		return max
+	}
+
+	return size
+}
+
+// withSearchOptions returns the arguments of the query condition without the search options, and
+// a copy of the repository applying them after the given options, or the repository itself when there is none.
+func (r *Repository[T]) withSearchOptions(args []interface{}, opts ...SearchOption) (*Repository[T], []interface{}, error) {
+	args, sc, err := splitSearchOptions(r.schema, args, opts...)
+	if err != nil || sc == nil {
+		return r, args, err
+	}
+
+	if sc.lock != nil {
+		if _, err = r.lockClause(sc.lock); err != nil {
+			return nil, nil, fmt.Errorf("invalid search option: %v", err)
+		}
+	}
+
+	repo := *r
+	repo.search = sc
+
+	return &repo, args, nil
+}
+
+// withSearchOptions returns the arguments of the query condition without the search options, and
+// a copy of the repository applying them after the given options, or the repository itself when there is none.
+func (m *MemoryRepository[T]) withSearchOptions(args []interface{}, opts ...SearchOption) (*MemoryRepository[T], []interface{}, error) {
+	args, sc, err := splitSearchOptions(m.schema, args, opts...)
+	if err != nil || sc == nil {
+		return m, args, err
+	}
+
+	repo := *m
+	repo.search = sc
+
+	return &repo, args, nil
+}
+
+// searchSort returns the order clause of a search: the one of the search options, or the default sort.
+func (c *config) searchSort(sc *searchConfig) string {
+	if sc != nil && sc.sort != "" {
+		return sc.sort
+	}
+
+	return c.defaultSort
+}
+
+// searchPageSize returns the page size of a search: the one of the search options, or the one of the
+// repository, clamped to the max page size.
+func (c *config) searchPageSize(sc *searchConfig, repoSize uint) uint {
+	if sc != nil && sc.pageSize != nil {
+		return clampPageSize(*sc.pageSize, c.maxPageSize)
+	}
+
+	return clampPageSize(repoSize, c.maxPageSize)
+}
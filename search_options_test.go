@@ -0,0 +1,195 @@
+package gormet
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testSearchAuthor struct {
+	gorm.Model
+	Name  string           `json:"name"`
+	Group string           `json:"group"`
+	Books []testSearchBook `gorm:"foreignKey:AuthorID"`
+}
+
+type testSearchBook struct {
+	gorm.Model
+	Title    string `json:"title"`
+	AuthorID uint   `json:"authorId"`
+}
+
+func TestRepository_SearchOptions(t *testing.T) {
+	db := getGormConnection(t, &testSearch{})
+
+	repo, err := New[testSearch](db, WithPageSize(10), WithMaxPageSize(20))
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	createMany(repo, 30, group)
+
+	t.Run("Page size override", func(t *testing.T) {
+		resp, err := repo.Search(1, "filter = ?", group, SearchPageSize(5))
+
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 5)
+		assert.Equal(t, uint(5), resp.Response.PageSize)
+		assert.Equal(t, int64(6), resp.Response.TotalPages)
+		assert.Equal(t, uint(10), repo.PageSize)
+	})
+
+	t.Run("Page size capped by the max", func(t *testing.T) {
+		resp, err := repo.Search(1, "filter = ?", group, SearchPageSize(1000))
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 20)
+
+		resp, err = repo.Search(1, "filter = ?", group, SearchPageSize(0))
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 20)
+	})
+
+	t.Run("Typed options", func(t *testing.T) {
+		opts := []SearchOption{SearchPageSize(5), SearchSort("name DESC")}
+
+		resp, err := repo.SearchWith(2, opts, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 5)
+		assert.Equal(t, uint(5), resp.Response.PageSize)
+		assert.Equal(t, int64(30), resp.Response.TotalCount)
+
+		for i := 1; i < len(resp.Response.Entities); i++ {
+			assert.GreaterOrEqual(t, resp.Response.Entities[i-1].Name, resp.Response.Entities[i].Name)
+		}
+
+		// Without options SearchWith behaves as Search.
+		resp, err = repo.SearchWith(1, nil, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 10)
+
+		_, err = repo.SearchWith(1, []SearchOption{SearchSort("unknown ASC")}, "filter = ?", group)
+		assert.Equal(t, `invalid search option: unknown sort column: "unknown"`, err.Error())
+
+		entities, err := repo.SearchAllWith(opts, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Len(t, entities, 30)
+
+		slice, err := repo.SearchSliceWith(2, opts, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.True(t, slice.HasNextPage)
+		assert.Equal(t, entities[5:10], slice.Entities)
+
+		unpaged, err := repo.SearchUnpagedWith([]SearchOption{SearchSelect("id")}, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Len(t, unpaged.Response.Entities, 30)
+		assert.Empty(t, unpaged.Response.Entities[0].Name)
+
+		count, err := repo.CountWith(nil, "filter = ?", group)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(30), count)
+
+		_, err = repo.CountWith([]SearchOption{nil}, "filter = ?", group)
+		assert.Equal(t, "invalid search option: the option should not be nil", err.Error())
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		entities, err := repo.SearchAll("filter = ?", group, SearchSort("name DESC"))
+		assert.Nil(t, err)
+		assert.Len(t, entities, 30)
+
+		for i := 1; i < len(entities); i++ {
+			assert.GreaterOrEqual(t, entities[i-1].Name, entities[i].Name)
+		}
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		slice, err := repo.SearchSlice(1, "filter = ?", group, SearchSelect("ID", "name"))
+
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 10)
+		assert.NotZero(t, slice.Entities[0].ID)
+		assert.NotEmpty(t, slice.Entities[0].Name)
+		assert.Empty(t, slice.Entities[0].Email)
+		assert.Empty(t, slice.Entities[0].Filter)
+	})
+
+	t.Run("Only search options", func(t *testing.T) {
+		resp, err := repo.SearchUnpaged(fmt.Sprintf("filter = '%s'", group), SearchSort("id ASC"))
+
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 30)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := repo.Search(1, "filter = ?", group, SearchSort("unknown ASC"))
+		assert.Equal(t, `invalid search option: unknown sort column: "unknown"`, err.Error())
+
+		_, err = repo.Search(1, "filter = ?", group, SearchSort(""))
+		assert.NotNil(t, err)
+
+		_, err = repo.SearchAll("filter = ?", group, SearchSelect("unknown"))
+		assert.NotNil(t, err)
+
+		_, err = repo.SearchSlice(1, "filter = ?", group, SearchSelect())
+		assert.NotNil(t, err)
+
+		_, err = repo.SearchUnpaged("filter = ?", group, SearchPreload("Unknown"))
+		assert.NotNil(t, err)
+
+		_, err = repo.Search(1, "filter = ?", group, SearchOption(nil))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Concurrent page sizes", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for size := uint(1); size <= 10; size++ {
+			wg.Add(1)
+
+			go func(size uint) {
+				defer wg.Done()
+
+				resp, err := repo.Search(1, "filter = ?", group, SearchPageSize(size))
+				assert.Nil(t, err)
+				assert.Len(t, resp.Response.Entities, int(size))
+			}(size)
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestRepository_SearchPreload(t *testing.T) {
+	db := getGormConnection(t, &testSearchAuthor{})
+	db.AutoMigrate(&testSearchBook{})
+
+	repo, err := New[testSearchAuthor](db)
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	author := &testSearchAuthor{
+		Name:  "author",
+		Group: group,
+		Books: []testSearchBook{{Title: "first"}, {Title: "second"}},
+	}
+	assert.Nil(t, repo.Create(author))
+
+	t.Run("Without preload", func(t *testing.T) {
+		entities, err := repo.SearchAll("`group` = ?", group)
+
+		assert.Nil(t, err)
+		assert.Len(t, entities, 1)
+		assert.Empty(t, entities[0].Books)
+	})
+
+	t.Run("With preload", func(t *testing.T) {
+		entities, err := repo.SearchAll("`group` = ?", group, SearchPreload("Books"))
+
+		assert.Nil(t, err)
+		assert.Len(t, entities, 1)
+		assert.Len(t, entities[0].Books, 2)
+	})
+}
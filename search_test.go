@@ -196,3 +196,16 @@ func Test_getLimit(t *testing.T) {
 		})
 	}
 }
+
+func Test_countTotalPages(t *testing.T) {
+	assert.Equal(t, int64(10), countTotalPages(100, 10, 10))
+	assert.Equal(t, int64(11), countTotalPages(101, 10, 10))
+	assert.Equal(t, int64(1), countTotalPages(100, -1, 0))
+	assert.Equal(t, int64(0), countTotalPages(0, -1, 0))
+}
+
+func Test_getHasNextPage(t *testing.T) {
+	assert.True(t, getHasNextPage(1, 10, 11))
+	assert.False(t, getHasNextPage(2, 10, 20))
+	assert.False(t, getHasNextPage(1, -1, 100))
+}
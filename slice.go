@@ -0,0 +1,154 @@
+package gormet
+
+// Slice represents a page of entities retrieved without counting the total number of matches.
+type Slice[T any] struct {
+	Entities    []T  `json:"entities"`
+	Page        uint `json:"page"`
+	PageSize    uint `json:"pageSize"`
+	HasNextPage bool `json:"hasNextPage"`
+	HasPrevPage bool `json:"hasPrevPage"`
+}
+
+// SearchSlice performs a paginated search for entities in the database based on given criteria,
+// without counting the total number of matches.
+//
+// Unlike Search, no COUNT query is issued: one more entity than the page size is fetched to know
+// whether a next page exists, and it is dropped from the result. This makes SearchSlice suitable for
+// high-traffic list endpoints, or "load more" navigation, where counting every match is too expensive.
+// When the repository has no page size, all the matching entities are returned in a single slice.
+//
+// Usage:
+// slice, err := repo.SearchSlice(page, "your_column = ?", "your_value")
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+//	if slice.HasNextPage {
+//	    // Render the link to the next page
+//	}
+//
+// Parameters:
+// - page: The page number for pagination (starting from 1).
+// - query: GORM query condition.
+// - args: Arguments for the query condition, optionally followed by search options such as SearchSort.
+//
+// Returns:
+// - A structure containing the entities of the page and whether the previous and next pages exist.
+// - An error if the search operation encounters any issues.
+func (r *Repository[T]) SearchSlice(page uint, query interface{}, args ...interface{}) (Slice[T], error) {
+	return r.SearchSliceWith(page, nil, query, args...)
+}
+
+// SearchSliceWith performs a paginated search without the total count as SearchSlice, customized by
+// the given search options.
+//
+// Usage:
+// slice, err := repo.SearchSliceWith(page, []gormet.SearchOption{gormet.SearchPageSize(50)}, "active = ?", true)
+//
+// Parameters:
+// - page: The page number for pagination (starting from 1).
+// - opts: The search options, such as SearchPageSize or SearchSort.
+// - query: GORM query condition or Specification.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - A structure containing the entities of the page and whether the previous and next pages exist.
+// - An error if an option is invalid or if the search operation encounters any issues.
+func (r *Repository[T]) SearchSliceWith(page uint, opts []SearchOption, query interface{}, args ...interface{}) (Slice[T], error) {
+	repo, args, err := r.withSearchOptions(args, opts...)
+	if err != nil {
+		return Slice[T]{}, err
+	}
+
+	var pageSize uint = repo.pageSize()
+	var offset int = getOffset(page, pageSize)
+	var limit int = getLimit(pageSize)
+
+	if limit > 0 {
+		limit++
+	}
+
+	entities, err := repo.executeSearch(offset, limit, query, args...)
+	if err != nil {
+		return Slice[T]{}, err
+	}
+
+	return newSlice(entities, page, pageSize), nil
+}
+
+// SearchUnpaged retrieves all the entities in the database matching the given criteria as a single page,
+// regardless of the page size of the repository.
+//
+// The total count is the number of entities retrieved, so no COUNT query is issued. The configured
+// max page size does not apply: use it only when the number of matches is known to be bounded.
+//
+// Usage:
+// pagination, err := repo.SearchUnpaged("your_column = ?", "your_value")
+//
+//	if err != nil {
+//	    // Handle error
+//	}
+//
+// Parameters:
+// - query: GORM query condition.
+// - args: Arguments for the query condition, optionally followed by search options such as SearchSort.
+//
+// Returns:
+// - A structure containing all the matching entities in a single page.
+// - An error if the search operation encounters any issues.
+func (r *Repository[T]) SearchUnpaged(query interface{}, args ...interface{}) (Pagination[T], error) {
+	return r.SearchUnpagedWith(nil, query, args...)
+}
+
+// SearchUnpagedWith retrieves all the matching entities as a single page as SearchUnpaged, customized
+// by the given search options.
+//
+// Usage:
+// pagination, err := repo.SearchUnpagedWith([]gormet.SearchOption{gormet.SearchSort("name ASC")}, "active = ?", true)
+//
+// Parameters:
+// - opts: The search options, such as SearchSort or SearchSelect.
+// - query: GORM query condition or Specification.
+// - args: Arguments for the query condition.
+//
+// Returns:
+// - A structure containing all the matching entities in a single page.
+// - An error if an option is invalid or if the search operation encounters any issues.
+func (r *Repository[T]) SearchUnpagedWith(opts []SearchOption, query interface{}, args ...interface{}) (Pagination[T], error) {
+	repo, args, err := r.withSearchOptions(args, opts...)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	entities, err := repo.executeSearch(-1, -1, query, args...)
+	if err != nil {
+		return Pagination[T]{}, err
+	}
+
+	pagination := Pagination[T]{
+		Response:   newResponse(entities, int64(len(entities)), 1, 0),
+		criteria:   query,
+		repository: r,
+	}
+
+	return pagination, nil
+}
+
+// newSlice builds the slice for the given page from the fetched entities, which may hold
+// one more entity than the page size to signal that a next page exists.
+func newSlice[T any](entities []T, page uint, pageSize uint) Slice[T] {
+	hasNext := pageSize > 0 && len(entities) > int(pageSize)
+
+	if hasNext {
+		entities = entities[:pageSize]
+	}
+
+	return Slice[T]{
+		Entities:    entities,
+		Page:        page,
+		PageSize:    pageSize,
+		HasNextPage: hasNext,
+		HasPrevPage: getHasPreviousPage(page),
+	}
+}
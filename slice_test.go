@@ -0,0 +1,102 @@
+package gormet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SearchSlice(t *testing.T) {
+	db := getGormConnection(t, &testSearch{})
+
+	repo, err := New[testSearch](db, WithPageSize(10))
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	createMany(repo, 25, group)
+
+	t.Run("First page", func(t *testing.T) {
+		slice, err := repo.SearchSlice(1, "filter = ?", group)
+
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 10)
+		assert.Equal(t, uint(1), slice.Page)
+		assert.Equal(t, uint(10), slice.PageSize)
+		assert.True(t, slice.HasNextPage)
+		assert.False(t, slice.HasPrevPage)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		slice, err := repo.SearchSlice(3, "filter = ?", group)
+
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 5)
+		assert.False(t, slice.HasNextPage)
+		assert.True(t, slice.HasPrevPage)
+	})
+
+	t.Run("Exactly full page", func(t *testing.T) {
+		full := uuid.NewString()
+		createMany(repo, 10, full)
+
+		slice, err := repo.SearchSlice(1, "filter = ?", full)
+
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 10)
+		assert.False(t, slice.HasNextPage)
+	})
+
+	t.Run("Without page size", func(t *testing.T) {
+		unpaged, err := New[testSearch](db)
+		assert.Nil(t, err)
+
+		slice, err := unpaged.SearchSlice(1, "filter = ?", group)
+
+		assert.Nil(t, err)
+		assert.Len(t, slice.Entities, 25)
+		assert.False(t, slice.HasNextPage)
+	})
+
+	t.Run("Request Error", func(t *testing.T) {
+		slice, err := repo.SearchSlice(1, "this_field_generate_error = ?", group)
+
+		assert.NotNil(t, err)
+		assert.Empty(t, slice.Entities)
+	})
+}
+
+func TestRepository_SearchUnpaged(t *testing.T) {
+	db := getGormConnection(t, &testSearch{})
+
+	repo, err := New[testSearch](db, WithPageSize(10), WithMaxPageSize(20))
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	createMany(repo, 25, group)
+
+	t.Run("All entities in one page", func(t *testing.T) {
+		resp, err := repo.SearchUnpaged("filter = ?", group)
+
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 25)
+		assert.Equal(t, int64(25), resp.Response.TotalCount)
+		assert.Equal(t, int64(1), resp.Response.TotalPages)
+		assert.Equal(t, uint(1), resp.Response.Page)
+		assert.False(t, resp.Response.HasNextPage)
+		assert.False(t, resp.Response.HasPrevPage)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		resp, err := repo.SearchUnpaged("filter = ?", uuid.NewString())
+
+		assert.Nil(t, err)
+		assert.Empty(t, resp.Response.Entities)
+		assert.Equal(t, int64(0), resp.Response.TotalPages)
+	})
+
+	t.Run("Request Error", func(t *testing.T) {
+		_, err := repo.SearchUnpaged("this_field_generate_error = ?", group)
+		assert.NotNil(t, err)
+	})
+}
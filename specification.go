@@ -0,0 +1,376 @@
+package gormet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Specification is a composable criteria for the entities of type T, accepted as the query of
+// Search, SearchSlice, SearchUnpaged, SearchAll, GetLatestN, Count and Export in place of a raw
+// SQL fragment.
+//
+// Column references are validated against the GORM schema of T when the specification is used, and
+// always quoted, while values are passed as bound parameters. This makes specifications safe to
+// build from user input such as the column names of a filter form. Specifications are immutable
+// values, so they can be reused and combined freely.
+//
+// Example:
+//
+//	spec := gormet.And(
+//		gormet.Eq[User]("active", true),
+//		gormet.Or(
+//			gormet.Like[User]("email", "%@example.com"),
+//			gormet.In[User]("role", []string{"admin", "owner"}),
+//		),
+//		gormet.Not(gormet.IsNull[User]("verified_at")),
+//	)
+//
+//	users, err := userRepo.SearchAll(spec)
+type Specification[T any] struct {
+	op       string             // The operator of the specification.
+	column   string             // The column compared by a leaf specification.
+	values   []interface{}      // The values compared by a leaf specification.
+	operands []Specification[T] // The specifications combined by And, Or and Not.
+	fold     bool               // Whether strings are compared case-insensitively.
+}
+
+// specification is implemented by Specification of any model type, so the in-memory criteria
+// parser can evaluate them and a specification built for another model can be refused.
+type specification interface {
+	expression(sch *schema.Schema) (clause.Expression, error)
+	predicate(sch *schema.Schema) (predicate, error)
+	model() reflect.Type
+}
+
+// truth is the result of a condition in the three-valued logic of SQL.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown // A comparison with NULL, matching neither the condition nor its negation.
+)
+
+// Eq matches the entities whose column is equal to the value.
+func Eq[T any](column string, value interface{}) Specification[T] {
+	return Specification[T]{op: "=", column: column, values: []interface{}{value}}
+}
+
+// Ne matches the entities whose column is not equal to the value.
+func Ne[T any](column string, value interface{}) Specification[T] {
+	return Specification[T]{op: "<>", column: column, values: []interface{}{value}}
+}
+
+// In matches the entities whose column is equal to one of the values, given as a slice.
+func In[T any](column string, values interface{}) Specification[T] {
+	return Specification[T]{op: "IN", column: column, values: toSlice(values)}
+}
+
+// Between matches the entities whose column is between the two values, inclusive.
+func Between[T any](column string, from interface{}, to interface{}) Specification[T] {
+	return Specification[T]{op: "BETWEEN", column: column, values: []interface{}{from, to}}
+}
+
+// Like matches the entities whose column matches the SQL LIKE pattern, such as "jo%".
+func Like[T any](column string, pattern string) Specification[T] {
+	return Specification[T]{op: "LIKE", column: column, values: []interface{}{pattern}}
+}
+
+// IsNull matches the entities whose column is NULL.
+func IsNull[T any](column string) Specification[T] {
+	return Specification[T]{op: "IS NULL", column: column}
+}
+
+// And matches the entities matching all the specifications.
+func And[T any](specs ...Specification[T]) Specification[T] {
+	return Specification[T]{op: "AND", operands: specs}
+}
+
+// Or matches the entities matching at least one of the specifications.
+func Or[T any](specs ...Specification[T]) Specification[T] {
+	return Specification[T]{op: "OR", operands: specs}
+}
+
+// Not matches the entities not matching the specification.
+func Not[T any](spec Specification[T]) Specification[T] {
+	return Specification[T]{op: "NOT", operands: []Specification[T]{spec}}
+}
+
+// expression converts the specification into a GORM clause, validating its columns against the schema.
+func (s Specification[T]) expression(sch *schema.Schema) (clause.Expression, error) {
+	switch s.op {
+	case "AND", "OR", "NOT":
+		if len(s.operands) == 0 {
+			return nil, fmt.Errorf("the %s specification should combine at least one specification", s.op)
+		}
+
+		exprs := make([]clause.Expression, len(s.operands))
+		for i, operand := range s.operands {
+			expr, err := operand.expression(sch)
+			if err != nil {
+				return nil, err
+			}
+
+			exprs[i] = expr
+		}
+
+		switch s.op {
+		case "AND":
+			return clause.And(exprs...), nil
+		case "OR":
+			return clause.Or(exprs...), nil
+		default:
+			return clause.Not(exprs...), nil
+		}
+	case "ALL":
+		return clause.Expr{SQL: "1 = 1"}, nil
+	case "":
+		return nil, errors.New("the specification should not be empty")
+	}
+
+	field, err := lookUpColumn(sch, s.column)
+	if err != nil {
+		return nil, err
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	switch s.op {
+	case "CONTAINS", "STARTS WITH", "ENDS WITH":
+		pattern := s.pattern()
+		if s.fold {
+			return clause.Expr{SQL: "LOWER(?) LIKE LOWER(?) ESCAPE '" + likeEscape + "'", Vars: []interface{}{column, pattern}}, nil
+		}
+
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []interface{}{column, pattern}}, nil
+	case "=":
+		if s.fold {
+			return clause.Expr{SQL: "LOWER(?) = LOWER(?)", Vars: []interface{}{column, s.values[0]}}, nil
+		}
+
+		return clause.Eq{Column: column, Value: s.values[0]}, nil
+	case "<>":
+		return clause.Neq{Column: column, Value: s.values[0]}, nil
+	case "IN":
+		return clause.IN{Column: column, Values: s.values}, nil
+	case "BETWEEN":
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, s.values[0], s.values[1]}}, nil
+	case "LIKE":
+		return clause.Like{Column: column, Value: s.values[0]}, nil
+	default:
+		return clause.Eq{Column: column, Value: nil}, nil
+	}
+}
+
+// model returns the model type the specification is built for.
+func (s Specification[T]) model() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// predicate converts the specification into a predicate evaluated in memory, validating its columns against the schema.
+func (s Specification[T]) predicate(sch *schema.Schema) (predicate, error) {
+	eval, err := s.truth(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(entity reflect.Value) bool {
+		return eval(entity) == truthTrue
+	}, nil
+}
+
+// truth converts the specification into a function evaluating it in memory with the three-valued logic
+// of SQL: a comparison with a NULL column is unknown, so neither the specification nor its Not matches.
+func (s Specification[T]) truth(sch *schema.Schema) (func(entity reflect.Value) truth, error) {
+	switch s.op {
+	case "AND", "OR", "NOT":
+		if len(s.operands) == 0 {
+			return nil, fmt.Errorf("the %s specification should combine at least one specification", s.op)
+		}
+
+		evals := make([]func(entity reflect.Value) truth, len(s.operands))
+		for i, operand := range s.operands {
+			eval, err := operand.truth(sch)
+			if err != nil {
+				return nil, err
+			}
+
+			evals[i] = eval
+		}
+
+		return func(entity reflect.Value) truth {
+			switch s.op {
+			case "AND", "OR":
+				// AND is decided by a false operand and OR by a true one, otherwise an unknown operand wins.
+				decisive, result := truthFalse, truthTrue
+				if s.op == "OR" {
+					decisive, result = truthTrue, truthFalse
+				}
+
+				for _, eval := range evals {
+					switch eval(entity) {
+					case decisive:
+						return decisive
+					case truthUnknown:
+						result = truthUnknown
+					}
+				}
+
+				return result
+			default:
+				switch evals[0](entity) {
+				case truthTrue:
+					return truthFalse
+				case truthFalse:
+					return truthTrue
+				default:
+					return truthUnknown
+				}
+			}
+		}, nil
+	case "ALL":
+		return func(entity reflect.Value) truth { return truthTrue }, nil
+	case "":
+		return nil, errors.New("the specification should not be empty")
+	}
+
+	field, err := lookUpColumn(sch, s.column)
+	if err != nil {
+		return nil, err
+	}
+
+	match := s.leafPredicate(field)
+
+	// IS NULL, and the equality with nil that GORM renders as IS NULL or IS NOT NULL, are never unknown.
+	nullable := s.op != "IS NULL" && !s.nilComparison()
+
+	return func(entity reflect.Value) truth {
+		if nullable && normalizeValue(fieldValue(field, entity)) == nil {
+			return truthUnknown
+		}
+
+		if match(entity) {
+			return truthTrue
+		}
+
+		return truthFalse
+	}, nil
+}
+
+// leafPredicate evaluates a specification comparing a column in memory.
+func (s Specification[T]) leafPredicate(field *schema.Field) predicate {
+	if s.fold || s.op == "CONTAINS" || s.op == "STARTS WITH" || s.op == "ENDS WITH" {
+		return s.stringPredicate(field)
+	}
+
+	var conditions []condition
+
+	switch {
+	case s.nilComparison() && s.op == "=":
+		conditions = []condition{{field: field, op: "IS NULL"}}
+	case s.nilComparison():
+		conditions = []condition{{field: field, op: "IS NOT NULL"}}
+	case s.op == "<>":
+		conditions = []condition{{field: field, op: "!=", values: s.values}}
+	case s.op == "BETWEEN":
+		conditions = []condition{
+			{field: field, op: ">=", values: s.values[:1]},
+			{field: field, op: "<=", values: s.values[1:]},
+		}
+	default:
+		conditions = []condition{{field: field, op: s.op, values: s.values}}
+	}
+
+	return func(entity reflect.Value) bool {
+		for _, c := range conditions {
+			if !c.match(entity) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// nilComparison reports whether the specification compares the column with nil, which GORM renders as
+// IS NULL for Eq and IS NOT NULL for Ne.
+func (s Specification[T]) nilComparison() bool {
+	return (s.op == "=" || s.op == "<>") && !s.fold && normalizeValue(s.values[0]) == nil
+}
+
+// pattern returns the LIKE pattern of a string matching specification.
+func (s Specification[T]) pattern() string {
+	text := escapeLike(fmt.Sprint(s.values[0]))
+
+	switch s.op {
+	case "STARTS WITH":
+		return text + "%"
+	case "ENDS WITH":
+		return "%" + text
+	default:
+		return "%" + text + "%"
+	}
+}
+
+// stringPredicate evaluates a string matching specification in memory.
+func (s Specification[T]) stringPredicate(field *schema.Field) predicate {
+	value := fmt.Sprint(s.values[0])
+
+	return func(entity reflect.Value) bool {
+		text, ok := normalizeValue(fieldValue(field, entity)).(string)
+		if !ok {
+			return false
+		}
+
+		expected := value
+		if s.fold {
+			text, expected = strings.ToLower(text), strings.ToLower(expected)
+		}
+
+		switch s.op {
+		case "CONTAINS":
+			return strings.Contains(text, expected)
+		case "STARTS WITH":
+			return strings.HasPrefix(text, expected)
+		case "ENDS WITH":
+			return strings.HasSuffix(text, expected)
+		default:
+			return text == expected
+		}
+	}
+}
+
+// criteria resolves the query of a read operation: a Specification is validated against the schema and
+// converted into a GORM clause, while any other query is returned unchanged with its arguments.
+func (r *Repository[T]) criteria(query interface{}, args []interface{}) (interface{}, []interface{}, error) {
+	spec, ok := query.(Specification[T])
+	if !ok {
+		// A specification of another model would be taken by GORM as a struct condition.
+		if other, ok := query.(specification); ok {
+			return nil, nil, foreignSpecification(other, r.schema)
+		}
+
+		return query, args, nil
+	}
+
+	if len(args) > 0 {
+		return nil, nil, errors.New("a specification takes no arguments")
+	}
+
+	expr, err := spec.expression(r.schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid specification: %v", err)
+	}
+
+	return expr, nil, nil
+}
+
+// foreignSpecification returns the error reported for a specification built for another model.
+func foreignSpecification(spec specification, sch *schema.Schema) error {
+	return fmt.Errorf("invalid specification: built for %s, expected %s", spec.model(), sch.ModelType)
+}
@@ -0,0 +1,134 @@
+package gormet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testSpec struct {
+	gorm.Model
+	Name  string  `json:"name" gorm:"unique;not null;default:null"`
+	Group string  `json:"group"`
+	Age   int     `json:"age"`
+	Note  *string `json:"note"`
+}
+
+func createSpecs(t *testing.T, repo RepositoryAPI[testSpec], group string) {
+	note := "note"
+
+	for age := 1; age <= 10; age++ {
+		entity := &testSpec{Name: fmt.Sprintf("%s-%02d", group, age), Group: group, Age: age}
+		if age%2 == 0 {
+			entity.Note = &note
+		}
+
+		assert.Nil(t, repo.Create(entity))
+	}
+}
+
+func TestSpecification(t *testing.T) {
+	db := getGormConnection(t, &testSpec{})
+
+	repo, err := New[testSpec](db, WithDefaultSort("age ASC"))
+	assert.Nil(t, err)
+
+	memory, err := NewMemory[testSpec](WithDefaultSort("age ASC"))
+	assert.Nil(t, err)
+
+	group := uuid.NewString()
+	createSpecs(t, repo, group)
+	createSpecs(t, memory, group)
+
+	inGroup := Eq[testSpec]("group", group)
+
+	tests := []struct {
+		name string
+		spec Specification[testSpec]
+		ages []int
+	}{
+		{name: "Eq", spec: And(inGroup, Eq[testSpec]("age", 3)), ages: []int{3}},
+		{name: "Ne", spec: And(inGroup, Ne[testSpec]("age", 3), Between[testSpec]("age", 2, 4)), ages: []int{2, 4}},
+		{name: "In", spec: And(inGroup, In[testSpec]("age", []int{1, 5, 9})), ages: []int{1, 5, 9}},
+		{name: "Between", spec: And(inGroup, Between[testSpec]("Age", 4, 6)), ages: []int{4, 5, 6}},
+		{name: "Like", spec: Like[testSpec]("name", group+"-1%"), ages: []int{10}},
+		{name: "IsNull", spec: And(inGroup, IsNull[testSpec]("note"), Between[testSpec]("age", 1, 5)), ages: []int{1, 3, 5}},
+		{name: "Or", spec: And(inGroup, Or(Eq[testSpec]("age", 1), Eq[testSpec]("age", 10))), ages: []int{1, 10}},
+		{name: "Not", spec: And(inGroup, Not(Or(IsNull[testSpec]("note"), In[testSpec]("age", []int{2, 4, 6})))), ages: []int{8, 10}},
+		{name: "Not on NULL", spec: And(inGroup, Not(Like[testSpec]("note", "x%"))), ages: []int{2, 4, 6, 8, 10}},
+		{name: "Eq nil", spec: And(inGroup, Eq[testSpec]("note", nil), Between[testSpec]("age", 1, 5)), ages: []int{1, 3, 5}},
+		{name: "Ne nil", spec: And(inGroup, Ne[testSpec]("note", nil), Between[testSpec]("age", 1, 5)), ages: []int{2, 4}},
+		{name: "Not Eq nil", spec: And(inGroup, Not(Eq[testSpec]("note", (*string)(nil))), Between[testSpec]("age", 1, 5)), ages: []int{2, 4}},
+		{name: "Not of unknown Or", spec: And(inGroup, Not(Or(Eq[testSpec]("age", 1), Eq[testSpec]("note", "x")))), ages: []int{2, 4, 6, 8, 10}},
+	}
+
+	for _, tt := range tests {
+		for name, api := range map[string]RepositoryAPI[testSpec]{"database": repo, "memory": memory} {
+			t.Run(tt.name+" in "+name, func(t *testing.T) {
+				entities, err := api.SearchAll(tt.spec)
+				assert.Nil(t, err)
+
+				ages := make([]int, len(entities))
+				for i, entity := range entities {
+					ages[i] = entity.Age
+				}
+
+				assert.Equal(t, tt.ages, ages)
+
+				count, err := api.Count(tt.spec)
+				assert.Nil(t, err)
+				assert.Equal(t, int64(len(tt.ages)), count)
+			})
+		}
+	}
+
+	t.Run("Search and count", func(t *testing.T) {
+		repo.PageSize = 3
+
+		resp, err := repo.Search(2, inGroup)
+		assert.Nil(t, err)
+		assert.Len(t, resp.Response.Entities, 3)
+		assert.Equal(t, 4, resp.Response.Entities[0].Age)
+		assert.Equal(t, int64(10), resp.Response.TotalCount)
+
+		latest, err := repo.GetLatestN(2, inGroup)
+		assert.Nil(t, err)
+		assert.Len(t, latest, 2)
+		assert.Equal(t, 10, latest[0].Age)
+
+		count, err := repo.Count(nil)
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, count, int64(10))
+	})
+
+	t.Run("Value is not injected", func(t *testing.T) {
+		entities, err := repo.SearchAll(Eq[testSpec]("name", "x' OR '1'='1"))
+		assert.Nil(t, err)
+		assert.Empty(t, entities)
+	})
+
+	t.Run("Invalid specifications", func(t *testing.T) {
+		for name, api := range map[string]RepositoryAPI[testSpec]{"database": repo, "memory": memory} {
+			_, err := api.SearchAll(Eq[testSpec]("name = name OR 1", 1))
+			assert.Equal(t, `invalid specification: unknown column: "name = name OR 1"`, err.Error(), name)
+
+			_, err = api.Count(And(inGroup, Eq[testSpec]("unknown", 1)))
+			assert.NotNil(t, err, name)
+
+			_, err = api.SearchAll(Or[testSpec]())
+			assert.NotNil(t, err, name)
+
+			_, err = api.SearchAll(Specification[testSpec]{})
+			assert.NotNil(t, err, name)
+
+			_, err = api.SearchAll(inGroup, 1)
+			assert.NotNil(t, err, name)
+
+			_, err = api.Count(Eq[testSearch]("name", "x"))
+			assert.Equal(t, "invalid specification: built for gormet.testSearch, expected gormet.testSpec", err.Error(), name)
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Change is a field of a tracked entity whose value differs from the one loaded from the database.
+type Change struct {
+	Field  string      // The name of the field in the struct.
+	Column string      // The name of the column in the database.
+	Old    interface{} // The value loaded from the database.
+	New    interface{} // The current value of the field.
+}
+
+// tracker holds the snapshots of the entities loaded by a tracked repository, per primary key.
+type tracker struct {
+	mu        sync.Mutex
+	snapshots map[string]map[string]interface{}
+}
+
+// Tracked returns a copy of the repository tracking the entities it loads, to be used for the scope
+// of a unit of work such as a request.
+//
+// The entities loaded by the Get, GetById, GetLatest and Search functions of the copy are snapshotted,
+// as are the entities it creates or updates. Update then writes only the columns of a tracked entity
+// that changed since its snapshot, and issues no statement at all when nothing changed; untracked
+// entities are saved as a whole, as usual. Changes lists the modified fields of a tracked entity.
+// Within a transaction, the snapshots of the written entities are only updated once it is committed.
+// The snapshots are released with the copy, so it should not outlive the unit of work.
+//
+// Usage:
+//
+//	session := userRepo.Tracked()
+//
+//	user, err := session.GetById(userId)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+//	user.Email = "john@example.com"
+//
+//	// UPDATE users SET email = ?, updated_at = ? WHERE id = ?
+//	err = session.Update(user)
+//
+// Returns:
+// - A copy of the repository with its own set of snapshots.
+func (r *Repository[T]) Tracked() *Repository[T] {
+	repo := *r
+	repo.tracker = &tracker{snapshots: make(map[string]map[string]interface{})}
+
+	return &repo
+}
+
+// Changes lists the fields of a tracked entity whose values differ from its snapshot, in the order
+// of the fields of the model. Automatically updated timestamps, such as UpdatedAt, are ignored.
+//
+// Usage:
+//
+//	changes, err := session.Changes(user)
+//	for _, change := range changes {
+//		log.Printf("%s: %v -> %v", change.Field, change.Old, change.New)
+//	}
+//
+// Parameters:
+// - entity: A pointer to an entity loaded by the tracked repository.
+//
+// Returns:
+// - The changed fields, empty if none changed.
+// - An error if the repository is not tracked or if the entity was not loaded by it.
+func (r *Repository[T]) Changes(entity *T) ([]Change, error) {
+	if entity == nil {
+		return []Change{}, errors.New("the entity should not be nil")
+	}
+
+	if r.tracker == nil {
+		return []Change{}, errors.New("the repository is not tracked, use Tracked")
+	}
+
+	snapshot, ok := r.tracker.get(r.trackerKey(entity))
+	if !ok {
+		return []Change{}, errors.New("the entity is not tracked")
+	}
+
+	return r.changes(snapshot, entity), nil
+}
+
+// updateTracked writes the changed columns of a tracked entity. It reports false when the entity is
+// not tracked, so the caller saves it as a whole.
+func (r *Repository[T]) updateTracked(entity *T) (bool, error) {
+	if r.tracker == nil {
+		return false, nil
+	}
+
+	snapshot, ok := r.tracker.get(r.trackerKey(entity))
+	if !ok {
+		return false, nil
+	}
+
+	changes := r.changes(snapshot, entity)
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	columns := make([]string, len(changes))
+	for i, change := range changes {
+		columns[i] = change.Column
+	}
+
+	err := r.write(opUpdate, entity, func(tx *gorm.DB) error {
+		result := tx.Model(entity).Select(columns).Updates(entity)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// The entity was deleted since it was loaded.
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return true, err
+	}
+
+	r.trackWritten(entity)
+	r.invalidate(r.primaryKeyValue(entity))
+
+	return true, nil
+}
+
+// track snapshots the given entities, when the repository is tracked.
+func (r *Repository[T]) track(entities ...*T) {
+	if r.tracker == nil {
+		return
+	}
+
+	for _, entity := range entities {
+		r.tracker.set(r.trackerKey(entity), r.snapshot(entity))
+	}
+}
+
+// trackWritten snapshots the entities written by the repository once the enclosing transaction is
+// committed, so the changes of a write rolled back with its transaction are written again by the next Update.
+func (r *Repository[T]) trackWritten(entities ...*T) {
+	if r.tracker == nil {
+		return
+	}
+
+	for _, entity := range entities {
+		key, snapshot := r.trackerKey(entity), r.snapshot(entity)
+		r.afterCommit(func() { r.tracker.set(key, snapshot) })
+	}
+}
+
+// trackAll snapshots the entities of a search, when the repository is tracked.
+func (r *Repository[T]) trackAll(entities []T) {
+	if r.tracker == nil {
+		return
+	}
+
+	for i := range entities {
+		r.track(&entities[i])
+	}
+}
+
+// untrack drops the snapshot of the entity with the given id, when the repository is tracked.
+func (r *Repository[T]) untrack(id interface{}) {
+	if r.tracker != nil {
+		r.tracker.delete(fmt.Sprintf("%v", id))
+	}
+}
+
+// trackerKey returns the key of the snapshot of the entity.
+func (r *Repository[T]) trackerKey(entity *T) string {
+	return fmt.Sprintf("%v", r.primaryKeyValue(entity))
+}
+
+// snapshot copies the values of the tracked columns of the entity.
+func (r *Repository[T]) snapshot(entity *T) map[string]interface{} {
+	value := reflect.ValueOf(entity).Elem()
+	snapshot := make(map[string]interface{})
+
+	for _, field := range r.trackedFields() {
+		snapshot[field.DBName] = cloneValue(field.ReflectValueOf(context.Background(), value)).Interface()
+	}
+
+	return snapshot
+}
+
+// changes compares the tracked columns of the entity with the snapshot.
+func (r *Repository[T]) changes(snapshot map[string]interface{}, entity *T) []Change {
+	value := reflect.ValueOf(entity).Elem()
+	changes := make([]Change, 0)
+
+	for _, field := range r.trackedFields() {
+		current := field.ReflectValueOf(context.Background(), value).Interface()
+
+		if !reflect.DeepEqual(snapshot[field.DBName], current) {
+			changes = append(changes, Change{
+				Field:  field.Name,
+				Column: field.DBName,
+				Old:    snapshot[field.DBName],
+				New:    current,
+			})
+		}
+	}
+
+	return changes
+}
+
+// trackedFields returns the fields compared by the dirty tracking: the updatable columns, except the
+// primary keys and the timestamps updated automatically.
+func (r *Repository[T]) trackedFields() []*schema.Field {
+	fields := make([]*schema.Field, 0, len(r.schema.Fields))
+
+	for _, field := range r.schema.Fields {
+		if field.DBName != "" && field.Updatable && !field.PrimaryKey && field.AutoUpdateTime == 0 {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// cloneValue copies a value deeply, so the snapshot is not changed through the maps, slices and
+// pointers it shares with the entity.
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.New(value.Type().Elem())
+		clone.Elem().Set(cloneValue(value.Elem()))
+
+		return clone
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			clone.Index(i).Set(cloneValue(value.Index(i)))
+		}
+
+		return clone
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			clone.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+
+		return clone
+	case reflect.Struct:
+		clone := reflect.New(value.Type()).Elem()
+		clone.Set(value)
+
+		for i := 0; i < value.NumField(); i++ {
+			if clone.Field(i).CanSet() {
+				clone.Field(i).Set(cloneValue(value.Field(i)))
+			}
+		}
+
+		return clone
+	default:
+		return value
+	}
+}
+
+// get returns the snapshot stored under the key.
+func (t *tracker) get(key string) (map[string]interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot, ok := t.snapshots[key]
+	return snapshot, ok
+}
+
+// set stores the snapshot under the key.
+func (t *tracker) set(key string, snapshot map[string]interface{}) {
+	t.mu.Lock()
+	t.snapshots[key] = snapshot
+	t.mu.Unlock()
+}
+
+// delete removes the snapshot stored under the key.
+func (t *tracker) delete(key string) {
+	t.mu.Lock()
+	delete(t.snapshots, key)
+	t.mu.Unlock()
+}
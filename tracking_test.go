@@ -0,0 +1,257 @@
+package gormet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testTracking struct {
+	gorm.Model
+	Name  string            `json:"name"`
+	Email string            `json:"email"`
+	Age   int               `json:"age"`
+	Tags  map[string]string `json:"tags" gorm:"serializer:json;type:text"`
+}
+
+// recordUpdates collects the SQL of the UPDATE statements issued through the connection.
+func recordUpdates(t *testing.T, db *gorm.DB) *[]string {
+	statements := &[]string{}
+
+	err := db.Callback().Update().After("gorm:update").Register("test:record_updates", func(tx *gorm.DB) {
+		*statements = append(*statements, tx.Statement.SQL.String())
+	})
+	assert.Nil(t, err)
+
+	return statements
+}
+
+func TestTracked(t *testing.T) {
+	db := getGormConnection(t, &testTracking{})
+	statements := recordUpdates(t, db)
+
+	repo, err := New[testTracking](db)
+	assert.Nil(t, err)
+
+	create := func() *testTracking {
+		entity := &testTracking{Name: uuid.NewString(), Email: "old@mail.com", Age: 5, Tags: map[string]string{"a": "1"}}
+		assert.Nil(t, repo.Create(entity))
+
+		return entity
+	}
+
+	t.Run("Update writes the changed columns", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		loaded, err := session.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		loaded.Email = "new@mail.com"
+		loaded.Age = 0
+
+		changes, err := session.Changes(loaded)
+		assert.Nil(t, err)
+		assert.Equal(t, []Change{
+			{Field: "Email", Column: "email", Old: "old@mail.com", New: "new@mail.com"},
+			{Field: "Age", Column: "age", Old: 5, New: 0},
+		}, changes)
+
+		*statements = (*statements)[:0]
+		assert.Nil(t, session.Update(loaded))
+
+		assert.Len(t, *statements, 1)
+		assert.Contains(t, (*statements)[0], "`email`")
+		assert.Contains(t, (*statements)[0], "`age`")
+		assert.Contains(t, (*statements)[0], "`updated_at`")
+		assert.NotContains(t, (*statements)[0], "`name`")
+
+		stored, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "new@mail.com", stored.Email)
+		assert.Equal(t, 0, stored.Age)
+		assert.Equal(t, entity.Name, stored.Name)
+
+		changes, err = session.Changes(loaded)
+		assert.Nil(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Update without changes issues no statement", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		loaded, err := session.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		*statements = (*statements)[:0]
+		assert.Nil(t, session.Update(loaded))
+		assert.Empty(t, *statements)
+	})
+
+	t.Run("Entities loaded by a search", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		entities, err := session.SearchAll("name = ?", entity.Name)
+		assert.Nil(t, err)
+		assert.Len(t, entities, 1)
+
+		// Changes made in place to a map are detected.
+		entities[0].Tags["a"] = "2"
+
+		changes, err := session.Changes(&entities[0])
+		assert.Nil(t, err)
+		assert.Len(t, changes, 1)
+		assert.Equal(t, "tags", changes[0].Column)
+
+		*statements = (*statements)[:0]
+		assert.Nil(t, session.Update(&entities[0]))
+		assert.Len(t, *statements, 1)
+
+		stored, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "2", stored.Tags["a"])
+	})
+
+	t.Run("Untracked entity is saved as a whole", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		untracked := *entity
+		untracked.Age = 6
+
+		_, err := session.Changes(&untracked)
+		assert.Equal(t, "the entity is not tracked", err.Error())
+
+		*statements = (*statements)[:0]
+		assert.Nil(t, session.Update(&untracked))
+		assert.Len(t, *statements, 1)
+		assert.Contains(t, (*statements)[0], "`name`")
+
+		// Once saved, the entity is tracked.
+		changes, err := session.Changes(&untracked)
+		assert.Nil(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Entity deleted since it was loaded", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		loaded, err := session.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, repo.DeleteById(entity.ID))
+
+		loaded.Age = 42
+		assert.Equal(t, gorm.ErrRecordNotFound, session.Update(loaded))
+	})
+
+	t.Run("Delete drops the snapshot", func(t *testing.T) {
+		entity := create()
+		session := repo.Tracked()
+
+		loaded, err := session.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, session.Delete(loaded))
+
+		_, err = session.Changes(loaded)
+		assert.Equal(t, "the entity is not tracked", err.Error())
+	})
+
+	t.Run("Repository not tracked", func(t *testing.T) {
+		entity := create()
+
+		_, err := repo.Changes(entity)
+		assert.Equal(t, "the repository is not tracked, use Tracked", err.Error())
+
+		_, err = repo.Tracked().Changes(nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTracked_RolledBack(t *testing.T) {
+	db := getGormConnection(t, &testTracking{})
+
+	// The first update of an entity with the "fail@mail.com" email fails.
+	failures := 1
+	repo, err := New[testTracking](db, WithHooks(Hooks{
+		BeforeUpdate: func(tx *gorm.DB, entity interface{}) error {
+			if entity.(*testTracking).Email == "fail@mail.com" && failures > 0 {
+				failures--
+				return errors.New("unavailable")
+			}
+
+			return nil
+		},
+	}))
+	assert.Nil(t, err)
+
+	load := func(session *Repository[testTracking]) *testTracking {
+		entity := &testTracking{Name: uuid.NewString(), Email: "old@mail.com"}
+		assert.Nil(t, repo.Create(entity))
+
+		loaded, err := session.GetById(entity.ID)
+		assert.Nil(t, err)
+
+		return loaded
+	}
+
+	t.Run("Transaction rolled back then committed", func(t *testing.T) {
+		session := repo.Tracked()
+		entity := load(session)
+		entity.Age = 42
+
+		err := session.Transaction(func(tx *Repository[testTracking]) error {
+			if err := tx.Update(entity); err != nil {
+				return err
+			}
+
+			return errors.New("abort")
+		})
+		assert.Equal(t, "abort", err.Error())
+
+		changes, err := session.Changes(entity)
+		assert.Nil(t, err)
+		assert.Len(t, changes, 1)
+
+		assert.Nil(t, session.Transaction(func(tx *Repository[testTracking]) error {
+			return tx.Update(entity)
+		}))
+
+		stored, err := repo.GetById(entity.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 42, stored.Age)
+
+		changes, err = session.Changes(entity)
+		assert.Nil(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Unit of work failed then committed", func(t *testing.T) {
+		session := repo.Tracked()
+		first := load(session)
+		second := load(session)
+
+		first.Age = 7
+		second.Email = "fail@mail.com"
+
+		uow := NewUnitOfWork(db)
+		assert.Nil(t, session.Enlist(uow).RegisterDirty(first))
+		assert.Nil(t, session.Enlist(uow).RegisterDirty(second))
+
+		assert.Equal(t, "unavailable", uow.Commit().Error())
+		assert.Nil(t, uow.Commit())
+
+		stored, err := repo.GetById(first.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 7, stored.Age)
+
+		stored, err = repo.GetById(second.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "fail@mail.com", stored.Email)
+	})
+}
@@ -0,0 +1,481 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Tree is an entity of a hierarchy with its nested children.
+type Tree[T any] struct {
+	Entity   T          // The entity of the node.
+	Children []*Tree[T] // The children of the node, ordered by primary key.
+}
+
+// MaxTreeDepth bounds the number of levels walked by the recursive queries, so a cycle in the data
+// can't make them run forever. Deeper levels are not retrieved.
+const MaxTreeDepth = 1000
+
+// treeAlias is the alias of the table of the model in the recursive queries.
+const treeAlias = "t"
+
+// errTreeDisabled is returned by the tree queries of a repository created without WithTree.
+var errTreeDisabled = errors.New("the tree mode is not enabled, use the WithTree option")
+
+// Children retrieves the direct children of the entity with the given id.
+//
+// Usage:
+//
+//	subcategories, err := categoryRepo.Children(categoryId)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the parent entity.
+//
+// Returns:
+// - The children, empty if the entity has none.
+// - An error if the tree mode is not enabled or if the search fails.
+func (r *Repository[T]) Children(id interface{}) ([]T, error) {
+	parent, err := r.parentField()
+	if err != nil {
+		return []T{}, err
+	}
+
+	if id == nil {
+		return []T{}, errors.New("the id should not be nil")
+	}
+
+	return r.SearchAll(fmt.Sprintf("%s = ?", r.quote(parent.DBName)), id)
+}
+
+// Roots retrieves the entities without a parent.
+//
+// Returns:
+// - The roots of the hierarchy.
+// - An error if the tree mode is not enabled or if the search fails.
+func (r *Repository[T]) Roots() ([]T, error) {
+	parent, err := r.parentField()
+	if err != nil {
+		return []T{}, err
+	}
+
+	condition, args := rootCondition(parent, r.quote(parent.DBName))
+
+	return r.SearchAll(condition, args...)
+}
+
+// Descendants retrieves the descendants of the entity with the given id down to the given depth,
+// level by level: the children first, then the grandchildren and so on. A depth of 0 retrieves all
+// the levels, up to MaxTreeDepth, which also caps greater depths. The hierarchy is walked by a single
+// recursive query.
+//
+// Usage:
+//
+//	// Retrieve the children and grandchildren of the category
+//	categories, err := categoryRepo.Descendants(categoryId, 2)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity.
+// - depth: The number of levels to retrieve, 0 for all of them up to MaxTreeDepth.
+//
+// Returns:
+// - The descendants, empty if the entity has none.
+// - An error if the tree mode is not enabled or if the query fails.
+func (r *Repository[T]) Descendants(id interface{}, depth uint) ([]T, error) {
+	parent, err := r.parentField()
+	if err != nil {
+		return []T{}, err
+	}
+
+	if id == nil {
+		return []T{}, errors.New("the id should not be nil")
+	}
+
+	return r.descend(fmt.Sprintf("%s = ?", r.quote(parent.DBName)), []interface{}{id}, depth)
+}
+
+// Ancestors retrieves the ancestors of the entity with the given id, from its parent up to the root,
+// or up to MaxTreeDepth levels.
+//
+// Usage:
+//
+//	// Build the breadcrumb of the category
+//	ancestors, err := categoryRepo.Ancestors(categoryId)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity.
+//
+// Returns:
+// - The ancestors, the parent first, empty for a root.
+// - An error if the tree mode is not enabled or if the query fails.
+func (r *Repository[T]) Ancestors(id interface{}) ([]T, error) {
+	parent, err := r.parentField()
+	if err != nil {
+		return []T{}, err
+	}
+
+	if id == nil {
+		return []T{}, errors.New("the id should not be nil")
+	}
+
+	scope, scopeArgs := r.scopeCondition()
+
+	// The recursion follows the parent column upwards: each level holds the parent of the previous one.
+	sql := fmt.Sprintf(`%s tree (node_id, depth) AS (
+	SELECT t.%s, 1 FROM %s t WHERE t.%s = ?%s%s
+	UNION ALL
+	SELECT t.%s, tree.depth + 1 FROM %s t JOIN tree ON t.%s = tree.node_id WHERE tree.depth < ?%s%s
+)
+SELECT t.* FROM %s t JOIN tree ON t.%s = tree.node_id%s%s ORDER BY tree.depth`,
+		r.withRecursive(),
+		r.quote(parent.DBName), r.quote(r.schema.Table), r.quote(r.pkName), r.notDeleted("t."), scope,
+		r.quote(parent.DBName), r.quote(r.schema.Table), r.quote(r.pkName), r.notDeleted("t."), scope,
+		r.quote(r.schema.Table), r.quote(r.pkName), r.notDeleted("t."), scope)
+
+	args := append(append([]interface{}{id}, scopeArgs...), MaxTreeDepth)
+	args = append(append(args, scopeArgs...), scopeArgs...)
+
+	return r.rawTree(sql, args...)
+}
+
+// Move changes the parent of the entity with the given id, in a transaction. A nil parent makes the
+// entity a root. The entity is written by Update, so hooks and history apply.
+//
+// Usage:
+//
+//	if err := categoryRepo.Move(categoryId, newParentId); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity to move.
+// - parent: The unique identifier of the new parent, nil to make the entity a root.
+//
+// Returns:
+// - nil if the entity is moved.
+// - An error if the new parent is the entity itself or one of its descendants, as the move would
+// create a cycle, if one of the entities does not exist or if the update fails. The cycle is detected
+// on the raw chain of parents, soft deleted entities and entities outside the default scopes included,
+// up to MaxTreeDepth levels.
+func (r *Repository[T]) Move(id interface{}, parent interface{}) error {
+	field, err := r.parentField()
+	if err != nil {
+		return err
+	}
+
+	if id == nil {
+		return errors.New("the id should not be nil")
+	}
+
+	return r.Transaction(func(tx *Repository[T]) error {
+		entity, err := tx.GetById(id)
+		if err != nil {
+			return err
+		}
+
+		value := reflect.Zero(field.FieldType).Interface()
+
+		if parent != nil {
+			if _, err := tx.GetById(parent); err != nil {
+				return fmt.Errorf("impossible to retrieve the new parent: %v", err)
+			}
+
+			// The new parent can't be the entity itself or one of its descendants.
+			cycle, err := tx.isAncestor(id, parent)
+			if err != nil {
+				return err
+			}
+
+			if cycle || treeKey(parent) == treeKey(id) {
+				return fmt.Errorf("moving %v under %v would create a cycle", id, parent)
+			}
+
+			value = parent
+		}
+
+		if err := field.Set(context.Background(), reflect.ValueOf(entity).Elem(), value); err != nil {
+			return fmt.Errorf("invalid parent: %v", err)
+		}
+
+		return tx.Update(entity)
+	})
+}
+
+// Tree retrieves the entity with the given id and its descendants down to the given depth, nested.
+// A depth of 0 retrieves all the levels, up to MaxTreeDepth.
+//
+// Usage:
+//
+//	tree, err := categoryRepo.Tree(categoryId, 0)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - id: The unique identifier of the entity at the root of the tree.
+// - depth: The number of levels of descendants to retrieve, 0 for all of them up to MaxTreeDepth.
+//
+// Returns:
+// - The tree rooted at the entity.
+// - An error if the tree mode is not enabled, if the entity does not exist or if the query fails.
+func (r *Repository[T]) Tree(id interface{}, depth uint) (*Tree[T], error) {
+	if _, err := r.parentField(); err != nil {
+		return nil, err
+	}
+
+	root, err := r.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := r.Descendants(id, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &Tree[T]{Entity: *root}
+	tree.Children = r.nest(descendants, treeKey(r.primaryKeyValue(root)))
+
+	return tree, nil
+}
+
+// Forest retrieves all the hierarchies, from their roots down to the given depth, nested.
+// A depth of 0 retrieves all the levels, up to MaxTreeDepth.
+//
+// Usage:
+//
+//	// Build a menu with the two first levels of categories
+//	menu, err := categoryRepo.Forest(2)
+//	if err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - depth: The number of levels to retrieve, including the roots, 0 for all of them up to MaxTreeDepth.
+//
+// Returns:
+// - The trees of the roots, ordered by primary key.
+// - An error if the tree mode is not enabled or if the query fails.
+func (r *Repository[T]) Forest(depth uint) ([]*Tree[T], error) {
+	parent, err := r.parentField()
+	if err != nil {
+		return []*Tree[T]{}, err
+	}
+
+	condition, args := rootCondition(parent, r.quote(parent.DBName))
+
+	nodes, err := r.descend(condition, args, depth)
+	if err != nil {
+		return []*Tree[T]{}, err
+	}
+
+	return r.nest(nodes, ""), nil
+}
+
+// descend retrieves the entities matching the anchor condition, then their descendants, level by level.
+func (r *Repository[T]) descend(anchor string, args []interface{}, depth uint) ([]T, error) {
+	parent, _ := r.parentField()
+
+	if depth == 0 || depth > MaxTreeDepth {
+		depth = MaxTreeDepth
+	}
+
+	scope, scopeArgs := r.scopeCondition()
+
+	// An entity hidden by the default scopes is not walked, so its descendants are hidden too.
+	sql := fmt.Sprintf(`%s tree (node_id, depth) AS (
+	SELECT t.%s, 1 FROM %s t WHERE %s%s%s
+	UNION ALL
+	SELECT t.%s, tree.depth + 1 FROM %s t JOIN tree ON t.%s = tree.node_id WHERE tree.depth < ?%s%s
+)
+SELECT t.* FROM %s t JOIN tree ON t.%s = tree.node_id%s ORDER BY tree.depth, t.%s`,
+		r.withRecursive(),
+		r.quote(r.pkName), r.quote(r.schema.Table), anchor, r.notDeleted("t."), scope,
+		r.quote(r.pkName), r.quote(r.schema.Table), r.quote(parent.DBName), r.notDeleted("t."), scope,
+		r.quote(r.schema.Table), r.quote(r.pkName), scope, r.quote(r.pkName))
+
+	args = append(append(args, scopeArgs...), depth)
+	args = append(append(args, scopeArgs...), scopeArgs...)
+
+	return r.rawTree(sql, args...)
+}
+
+// isAncestor reports whether the entity with the given id is an ancestor of the given node. The chain
+// of parents is walked as stored, ignoring the soft delete and the default scopes, so a hidden entity
+// can't hide a cycle.
+func (r *Repository[T]) isAncestor(id interface{}, node interface{}) (bool, error) {
+	parent, _ := r.parentField()
+
+	sql := fmt.Sprintf(`%s tree (node_id, depth) AS (
+	SELECT t.%s, 1 FROM %s t WHERE t.%s = ?
+	UNION ALL
+	SELECT t.%s, tree.depth + 1 FROM %s t JOIN tree ON t.%s = tree.node_id WHERE tree.depth < ?
+)
+SELECT COUNT(*) FROM tree WHERE node_id = ?`,
+		r.withRecursive(),
+		r.quote(parent.DBName), r.quote(r.schema.Table), r.quote(r.pkName),
+		r.quote(parent.DBName), r.quote(r.schema.Table), r.quote(r.pkName))
+
+	var count int64
+
+	err := r.retry(func() error {
+		return r.db.Raw(sql, node, MaxTreeDepth, id).Scan(&count).Error
+	})
+
+	return count > 0, err
+}
+
+// rawTree runs a recursive query of the tree.
+func (r *Repository[T]) rawTree(sql string, args ...interface{}) ([]T, error) {
+	entities := make([]T, 0)
+
+	err := r.retry(func() error {
+		entities = entities[:0]
+
+		// The criteria of the scopes refer to the table of the model as t, the alias used by the queries.
+		return r.db.Table(treeAlias).Raw(sql, args...).Scan(&entities).Error
+	})
+
+	if err != nil {
+		return []T{}, err
+	}
+
+	r.trackAll(entities)
+
+	return entities, nil
+}
+
+// nest builds the trees of the children of the given parent key, from entities ordered by level.
+func (r *Repository[T]) nest(entities []T, root string) []*Tree[T] {
+	parent, _ := r.parentField()
+
+	nodes := make(map[string]*Tree[T], len(entities))
+	roots := make([]*Tree[T], 0)
+
+	for i := range entities {
+		value := reflect.ValueOf(&entities[i]).Elem()
+
+		pk, _ := r.schema.LookUpField(r.pkName).ValueOf(context.Background(), value)
+		parentValue, _ := parent.ValueOf(context.Background(), value)
+
+		node := &Tree[T]{Entity: entities[i], Children: []*Tree[T]{}}
+		nodes[treeKey(pk)] = node
+
+		// A parent is always on a previous level, so it is already in the map.
+		if key := treeKey(parentValue); key == root {
+			roots = append(roots, node)
+		} else if owner, ok := nodes[key]; ok {
+			owner.Children = append(owner.Children, node)
+		}
+	}
+
+	return roots
+}
+
+// parentField returns the field of the parent column, or an error if the tree mode is not enabled.
+func (r *Repository[T]) parentField() (*schema.Field, error) {
+	if r.config.treeParent == "" {
+		return nil, errTreeDisabled
+	}
+
+	return lookUpDBField(r.schema, r.config.treeParent), nil
+}
+
+// withRecursive returns the keyword introducing a recursive common table expression in the dialect.
+func (r *Repository[T]) withRecursive() string {
+	if r.db.Dialector.Name() == "sqlserver" {
+		return "WITH"
+	}
+
+	return "WITH RECURSIVE"
+}
+
+// scopeCondition returns the default scopes as a condition on the table aliased t, appended to the
+// clauses of the recursive queries, with its argument. Both are empty when the repository is unscoped
+// or has no default scope.
+func (r *Repository[T]) scopeCondition() (string, []interface{}) {
+	if r.unscoped || len(r.config.defaultScopes) == 0 {
+		return "", nil
+	}
+
+	var exprs []clause.Expression
+	for _, s := range r.config.defaultScopes {
+		exprs = append(exprs, r.db.Statement.BuildCondition(s.query, s.args...)...)
+	}
+
+	return " AND (?)", []interface{}{clause.And(exprs...)}
+}
+
+// notDeleted returns the condition excluding the soft deleted rows, empty when the model has no gorm.DeletedAt field.
+func (r *Repository[T]) notDeleted(prefix string) string {
+	field := softDeleteField(r.schema)
+	if field == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" AND %s%s IS NULL", prefix, r.quote(field.DBName))
+}
+
+// quote quotes the name of a table or column for the dialect.
+func (r *Repository[T]) quote(name string) string {
+	return r.db.Statement.Quote(name)
+}
+
+// rootCondition returns the condition matching the entities without a parent: a null parent, or a
+// zero parent when the column is not nullable.
+func rootCondition(parent *schema.Field, column string) (string, []interface{}) {
+	if parent.FieldType.Kind() == reflect.Ptr {
+		return fmt.Sprintf("%s IS NULL", column), nil
+	}
+
+	return fmt.Sprintf("(%s IS NULL OR %s = ?)", column, column), []interface{}{reflect.Zero(parent.FieldType).Interface()}
+}
+
+// treeKey returns the key of a primary key or parent value, the empty string for a missing parent.
+func treeKey(value interface{}) string {
+	v := reflect.ValueOf(value)
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || v.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// validateTree checks that the parent column exists and has the type of the primary key.
+func validateTree(sch *schema.Schema, column string) error {
+	parent := lookUpDBField(sch, column)
+	if parent == nil {
+		return fmt.Errorf("unknown parent column: %q", column)
+	}
+
+	if len(sch.PrimaryFields) != 1 {
+		return errors.New("the tree mode requires a single primary key")
+	}
+
+	if parent.IndirectFieldType != sch.PrimaryFields[0].IndirectFieldType {
+		return fmt.Errorf("the parent column %q should have the type of the primary key", column)
+	}
+
+	return nil
+}
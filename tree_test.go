@@ -0,0 +1,273 @@
+package gormet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testTreeCategory struct {
+	gorm.Model
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId"`
+}
+
+type testTreeUnit struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	ParentID uint   `json:"parentId"`
+}
+
+// testTreeGroup has a parent column named after a reserved word, so it must always be quoted.
+type testTreeGroup struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Group *uint  `json:"group" gorm:"column:group"`
+}
+
+func TestTree(t *testing.T) {
+	db := getGormConnection(t, &testTreeCategory{})
+	assert.Nil(t, db.Exec("DELETE FROM test_tree_categories").Error)
+
+	repo, err := New[testTreeCategory](db, WithTree("parent_id"))
+	assert.Nil(t, err)
+
+	create := func(name string, parent *testTreeCategory) *testTreeCategory {
+		entity := &testTreeCategory{Name: name}
+		if parent != nil {
+			entity.ParentID = &parent.ID
+		}
+
+		assert.Nil(t, repo.Create(entity))
+		return entity
+	}
+
+	// books
+	// ├── fiction
+	// │   ├── fantasy
+	// │   │   └── epic
+	// │   └── crime
+	// └── science
+	// music
+	books := create("books", nil)
+	fiction := create("fiction", books)
+	science := create("science", books)
+	fantasy := create("fantasy", fiction)
+	crime := create("crime", fiction)
+	epic := create("epic", fantasy)
+	music := create("music", nil)
+
+	names := func(entities []testTreeCategory) []string {
+		result := make([]string, len(entities))
+		for i, entity := range entities {
+			result[i] = entity.Name
+		}
+
+		return result
+	}
+
+	t.Run("Children and roots", func(t *testing.T) {
+		children, err := repo.Children(books.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"fiction", "science"}, names(children))
+
+		roots, err := repo.Roots()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"books", "music"}, names(roots))
+	})
+
+	t.Run("Descendants", func(t *testing.T) {
+		descendants, err := repo.Descendants(books.ID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"fiction", "science", "fantasy", "crime", "epic"}, names(descendants))
+
+		descendants, err = repo.Descendants(books.ID, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"fiction", "science", "fantasy", "crime"}, names(descendants))
+
+		descendants, err = repo.Descendants(epic.ID, 0)
+		assert.Nil(t, err)
+		assert.Empty(t, descendants)
+	})
+
+	t.Run("Ancestors", func(t *testing.T) {
+		ancestors, err := repo.Ancestors(epic.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"fantasy", "fiction", "books"}, names(ancestors))
+
+		ancestors, err = repo.Ancestors(books.ID)
+		assert.Nil(t, err)
+		assert.Empty(t, ancestors)
+	})
+
+	t.Run("Tree and forest", func(t *testing.T) {
+		tree, err := repo.Tree(fiction.ID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, "fiction", tree.Entity.Name)
+		assert.Len(t, tree.Children, 2)
+		assert.Equal(t, "fantasy", tree.Children[0].Entity.Name)
+		assert.Equal(t, "epic", tree.Children[0].Children[0].Entity.Name)
+		assert.Empty(t, tree.Children[1].Children)
+
+		forest, err := repo.Forest(2)
+		assert.Nil(t, err)
+		assert.Len(t, forest, 2)
+		assert.Equal(t, "books", forest[0].Entity.Name)
+		assert.Len(t, forest[0].Children, 2)
+		assert.Empty(t, forest[0].Children[0].Children)
+		assert.Equal(t, "music", forest[1].Entity.Name)
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		assert.Nil(t, repo.Move(crime.ID, science.ID))
+
+		children, err := repo.Children(science.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"crime"}, names(children))
+
+		assert.Nil(t, repo.Move(science.ID, nil))
+
+		roots, err := repo.Roots()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"books", "science", "music"}, names(roots))
+
+		assert.Nil(t, repo.Move(science.ID, music.ID))
+	})
+
+	t.Run("Move creating a cycle", func(t *testing.T) {
+		err := repo.Move(fiction.ID, epic.ID)
+		assert.Equal(t, fmt.Sprintf("moving %d under %d would create a cycle", fiction.ID, epic.ID), err.Error())
+
+		err = repo.Move(fiction.ID, fiction.ID)
+		assert.NotNil(t, err)
+
+		stored, err := repo.GetById(fiction.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, books.ID, *stored.ParentID)
+	})
+
+	t.Run("Move to an unknown parent", func(t *testing.T) {
+		assert.NotNil(t, repo.Move(fiction.ID, uint(1<<30)))
+		assert.Equal(t, gorm.ErrRecordNotFound, repo.Move(uint(1<<30), nil))
+	})
+
+	t.Run("Soft deleted nodes are skipped", func(t *testing.T) {
+		assert.Nil(t, repo.Delete(fantasy))
+
+		descendants, err := repo.Descendants(fiction.ID, 0)
+		assert.Nil(t, err)
+		assert.Empty(t, descendants)
+	})
+}
+
+func TestTree_ZeroParent(t *testing.T) {
+	db := getGormConnection(t, &testTreeUnit{})
+	assert.Nil(t, db.Exec("DELETE FROM test_tree_units").Error)
+
+	repo, err := New[testTreeUnit](db, WithTree("ParentID"))
+	assert.Nil(t, err)
+
+	company := &testTreeUnit{Name: "company"}
+	assert.Nil(t, repo.Create(company))
+
+	sales := &testTreeUnit{Name: "sales", ParentID: company.ID}
+	assert.Nil(t, repo.Create(sales))
+
+	roots, err := repo.Roots()
+	assert.Nil(t, err)
+	assert.Len(t, roots, 1)
+	assert.Equal(t, "company", roots[0].Name)
+
+	forest, err := repo.Forest(0)
+	assert.Nil(t, err)
+	assert.Len(t, forest, 1)
+	assert.Equal(t, "sales", forest[0].Children[0].Entity.Name)
+
+	assert.Nil(t, repo.Move(sales.ID, nil))
+
+	stored, err := repo.GetById(sales.ID)
+	assert.Nil(t, err)
+	assert.Zero(t, stored.ParentID)
+}
+
+func TestTree_QuotedParent(t *testing.T) {
+	db := getGormConnection(t, &testTreeGroup{})
+	assert.Nil(t, db.Exec("DELETE FROM test_tree_groups").Error)
+
+	repo, err := New[testTreeGroup](db, WithTree("group"))
+	assert.Nil(t, err)
+
+	root := &testTreeGroup{Name: "root"}
+	assert.Nil(t, repo.Create(root))
+
+	child := &testTreeGroup{Name: "child", Group: &root.ID}
+	assert.Nil(t, repo.Create(child))
+
+	roots, err := repo.Roots()
+	assert.Nil(t, err)
+	assert.Len(t, roots, 1)
+	assert.Equal(t, "root", roots[0].Name)
+
+	children, err := repo.Children(root.ID)
+	assert.Nil(t, err)
+	assert.Len(t, children, 1)
+	assert.Equal(t, "child", children[0].Name)
+
+	forest, err := repo.Forest(0)
+	assert.Nil(t, err)
+	assert.Len(t, forest, 1)
+	assert.Len(t, forest[0].Children, 1)
+}
+
+func TestTree_MoveSoftDeletedCycle(t *testing.T) {
+	db := getGormConnection(t, &testTreeCategory{})
+	assert.Nil(t, db.Exec("DELETE FROM test_tree_categories").Error)
+
+	repo, err := New[testTreeCategory](db, WithTree("parent_id"))
+	assert.Nil(t, err)
+
+	x := &testTreeCategory{Name: "x"}
+	assert.Nil(t, repo.Create(x))
+
+	y := &testTreeCategory{Name: "y", ParentID: &x.ID}
+	assert.Nil(t, repo.Create(y))
+
+	z := &testTreeCategory{Name: "z", ParentID: &y.ID}
+	assert.Nil(t, repo.Create(z))
+
+	// The soft deleted node still links z to x.
+	assert.Nil(t, repo.Delete(y))
+
+	err = repo.Unscoped().Move(x.ID, z.ID)
+	assert.Equal(t, fmt.Sprintf("moving %d under %d would create a cycle", x.ID, z.ID), err.Error())
+
+	stored, err := repo.GetById(x.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, stored.ParentID)
+}
+
+func TestTree_Options(t *testing.T) {
+	db := getGormConnection(t, &testTreeCategory{})
+
+	_, err := New[testTreeCategory](db, WithTree("unknown"))
+	assert.Equal(t, `invalid option: unknown parent column: "unknown"`, err.Error())
+
+	_, err = New[testTreeCategory](db, WithTree("name"))
+	assert.Equal(t, `invalid option: the parent column "name" should have the type of the primary key`, err.Error())
+
+	_, err = New[testTreeCategory](db, WithTree(" "))
+	assert.NotNil(t, err)
+
+	repo, err := New[testTreeCategory](db)
+	assert.Nil(t, err)
+
+	_, err = repo.Children(1)
+	assert.Equal(t, errTreeDisabled, err)
+
+	_, err = repo.Forest(0)
+	assert.Equal(t, errTreeDisabled, err)
+
+	assert.Equal(t, errTreeDisabled, repo.Move(1, 2))
+}
@@ -0,0 +1,351 @@
+package gormet
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// UnitOfWork collects the entities created, modified and removed through the repositories enlisted
+// in it, possibly of different models, and writes them together in a single transaction on Commit.
+//
+// On Commit, the new entities are inserted first, parents before children, then the modified entities
+// are updated in registration order, and the removed entities are deleted last, children before parents.
+// The dependencies between the models are the belongs to, has one and has many relationships declared
+// in their structs; models with no declared relationship keep the registration order. The writes go
+// through the enlisted repositories, so their hooks and history apply as usual, while the cached
+// entities are invalidated once the transaction is committed.
+//
+// A unit of work is meant for a single request and is safe for concurrent registrations.
+type UnitOfWork struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	changes []*unitChange
+}
+
+// unitChange is an entity registered in a unit of work.
+type unitChange struct {
+	op     operation
+	entity interface{}                                                // A pointer to the entity, identifying it.
+	schema *schema.Schema                                             // The schema of the model of the entity.
+	write  func(tx *gorm.DB, op operation, committed *[]func()) error // Writes the entity through its repository, bound to the transaction.
+}
+
+// Enlistment registers the entities of a model in a unit of work, through the repository of the model.
+type Enlistment[T any] struct {
+	repo *Repository[T]
+	uow  *UnitOfWork
+}
+
+// NewUnitOfWork creates an empty unit of work writing to the given database, which should be the
+// database of the enlisted repositories.
+//
+// Usage:
+//
+//	uow := gormet.NewUnitOfWork(db)
+//
+//	users := userRepo.Enlist(uow)
+//	orders := orderRepo.Enlist(uow)
+//
+//	// The order is inserted after the user it belongs to
+//	orders.RegisterNew(order)
+//	users.RegisterNew(user)
+//
+//	if err := uow.Commit(); err != nil {
+//		// Handle error
+//	}
+//
+// Parameters:
+// - db: The database in which the changes are written.
+//
+// Returns:
+// - The unit of work.
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Enlist binds the repository to the unit of work, so its entities can be registered in it.
+//
+// Parameters:
+// - uow: The unit of work collecting the changes.
+//
+// Returns:
+// - The enlistment registering the entities of the repository.
+func (r *Repository[T]) Enlist(uow *UnitOfWork) *Enlistment[T] {
+	return &Enlistment[T]{repo: r, uow: uow}
+}
+
+// RegisterNew registers an entity to be created on Commit. Registering a new entity as removed
+// afterwards cancels its creation.
+//
+// Returns:
+// - An error if the entity is nil or was already registered as removed.
+func (e *Enlistment[T]) RegisterNew(entity *T) error {
+	return e.register(opCreate, entity)
+}
+
+// RegisterDirty registers an entity to be updated on Commit. A new entity stays new, so it is
+// created with its latest values.
+//
+// Returns:
+// - An error if the entity is nil or was already registered as removed.
+func (e *Enlistment[T]) RegisterDirty(entity *T) error {
+	return e.register(opUpdate, entity)
+}
+
+// RegisterRemoved registers an entity to be deleted on Commit, according to the soft delete strategy
+// of the repository.
+//
+// Returns:
+// - An error if the entity is nil.
+func (e *Enlistment[T]) RegisterRemoved(entity *T) error {
+	return e.register(opDelete, entity)
+}
+
+// register records the operation on the entity, merging it with a previous registration.
+func (e *Enlistment[T]) register(op operation, entity *T) error {
+	if entity == nil {
+		return errors.New("the entity should not be nil")
+	}
+
+	uow := e.uow
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+
+	for i, change := range uow.changes {
+		if change.entity != entity {
+			continue
+		}
+
+		switch {
+		case change.op == opDelete && op != opDelete:
+			return errors.New("the entity is registered as removed")
+		case change.op == opCreate && op == opDelete:
+			uow.changes = append(uow.changes[:i], uow.changes[i+1:]...)
+		case op == opDelete:
+			change.op = opDelete
+		}
+
+		return nil
+	}
+
+	repo := e.repo
+
+	uow.changes = append(uow.changes, &unitChange{
+		op:     op,
+		entity: entity,
+		schema: repo.schema,
+		write: func(tx *gorm.DB, op operation, committed *[]func()) error {
+			bound := repo.withTx(tx)
+			bound.committed = committed
+
+			switch op {
+			case opCreate:
+				return bound.Create(entity)
+			case opUpdate:
+				return bound.Update(entity)
+			default:
+				return bound.Delete(entity)
+			}
+		},
+	})
+
+	return nil
+}
+
+// Commit writes all the registered changes in a single transaction. On success the unit of work is
+// emptied; on failure nothing is written, the primary keys assigned to the new entities by the
+// rolled back inserts are cleared and the changes stay registered, so Commit can be retried or the
+// changes discarded with Rollback.
+//
+// Returns:
+// - nil if all the changes are written.
+// - The error of the first write that failed.
+func (u *UnitOfWork) Commit() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.changes) == 0 {
+		return nil
+	}
+
+	ordered := orderChanges(u.changes)
+	restore := newKeys(ordered)
+
+	// The functions run once the transaction is committed, such as the cache invalidations.
+	var committed []func()
+
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range ordered {
+			if err := change.write(tx, change.op, &committed); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		restore()
+		return err
+	}
+
+	for _, fn := range committed {
+		fn()
+	}
+
+	u.changes = nil
+	return nil
+}
+
+// Rollback discards all the registered changes. Nothing was written, so the database is untouched.
+func (u *UnitOfWork) Rollback() {
+	u.mu.Lock()
+	u.changes = nil
+	u.mu.Unlock()
+}
+
+// newKeys copies the primary keys of the new entities and returns the function restoring them.
+func newKeys(changes []*unitChange) func() {
+	var restores []func()
+
+	for _, change := range changes {
+		if change.op != opCreate {
+			continue
+		}
+
+		entity := reflect.ValueOf(change.entity).Elem()
+
+		for _, field := range change.schema.PrimaryFields {
+			target := field.ReflectValueOf(context.Background(), entity)
+
+			value := reflect.New(target.Type()).Elem()
+			value.Set(target)
+
+			restores = append(restores, func() { target.Set(value) })
+		}
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// orderChanges orders the changes for the flush: the inserts with the parents first, the updates
+// in registration order, then the deletes with the children first.
+func orderChanges(changes []*unitChange) []*unitChange {
+	rank := dependencyRank(changes)
+
+	var inserts, updates, deletes []*unitChange
+
+	for _, change := range changes {
+		switch change.op {
+		case opCreate:
+			inserts = append(inserts, change)
+		case opUpdate:
+			updates = append(updates, change)
+		default:
+			deletes = append(deletes, change)
+		}
+	}
+
+	sort.SliceStable(inserts, func(i, j int) bool {
+		return rank[inserts[i].schema.Table] < rank[inserts[j].schema.Table]
+	})
+
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return rank[deletes[i].schema.Table] > rank[deletes[j].schema.Table]
+	})
+
+	ordered := make([]*unitChange, 0, len(changes))
+	ordered = append(ordered, inserts...)
+	ordered = append(ordered, updates...)
+
+	return append(ordered, deletes...)
+}
+
+// dependencyRank sorts the tables of the changes topologically, so each table ranks after the tables
+// it references. Tables in a dependency cycle keep their registration order.
+func dependencyRank(changes []*unitChange) map[string]int {
+	var tables []string
+	schemas := make(map[string]*schema.Schema)
+
+	for _, change := range changes {
+		if _, ok := schemas[change.schema.Table]; !ok {
+			tables = append(tables, change.schema.Table)
+			schemas[change.schema.Table] = change.schema
+		}
+	}
+
+	// parents holds, for each table, the tables it references among the tables of the changes.
+	parents := make(map[string]map[string]bool, len(tables))
+	for _, table := range tables {
+		parents[table] = make(map[string]bool)
+	}
+
+	depend := func(child *schema.Schema, parent *schema.Schema) {
+		if child.Table == parent.Table || schemas[child.Table] == nil || schemas[parent.Table] == nil {
+			return
+		}
+
+		parents[child.Table][parent.Table] = true
+	}
+
+	for _, sch := range schemas {
+		for _, rel := range sch.Relationships.Relations {
+			switch rel.Type {
+			case schema.BelongsTo:
+				depend(rel.Schema, rel.FieldSchema)
+			case schema.HasOne, schema.HasMany:
+				depend(rel.FieldSchema, rel.Schema)
+			}
+		}
+	}
+
+	rank := make(map[string]int, len(tables))
+
+	for len(rank) < len(tables) {
+		next := ""
+
+		// The first table, in registration order, whose parents are all ranked.
+		for _, table := range tables {
+			if _, ok := rank[table]; ok {
+				continue
+			}
+
+			ready := true
+			for parent := range parents[table] {
+				if _, ok := rank[parent]; !ok {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				next = table
+				break
+			}
+		}
+
+		// A cycle: the first remaining table is ranked anyway.
+		if next == "" {
+			for _, table := range tables {
+				if _, ok := rank[table]; !ok {
+					next = table
+					break
+				}
+			}
+		}
+
+		rank[next] = len(rank)
+	}
+
+	return rank
+}
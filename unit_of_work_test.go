@@ -0,0 +1,262 @@
+package gormet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testUowAuthor struct {
+	ID    uint
+	Name  string        `gorm:"unique"`
+	Books []testUowBook `gorm:"foreignKey:AuthorID"`
+}
+
+type testUowBook struct {
+	ID       uint
+	Title    string
+	AuthorID uint
+	Author   *testUowAuthor
+}
+
+type testUowReview struct {
+	ID     uint
+	BookID uint
+	Book   *testUowBook
+	Stars  int
+}
+
+// recordWrites returns hooks appending the table and operation of each write to the log.
+func recordWrites(log *[]string, table string) Option {
+	return WithHooks(Hooks{
+		AfterCreate: func(tx *gorm.DB, entity interface{}) error {
+			*log = append(*log, "create "+table)
+			return nil
+		},
+		AfterUpdate: func(tx *gorm.DB, entity interface{}) error {
+			*log = append(*log, "update "+table)
+			return nil
+		},
+		AfterDelete: func(tx *gorm.DB, entity interface{}) error {
+			*log = append(*log, "delete "+table)
+			return nil
+		},
+	})
+}
+
+func TestUnitOfWork(t *testing.T) {
+	db := getGormConnection(t, &testUowAuthor{})
+	assert.Nil(t, db.AutoMigrate(&testUowBook{}, &testUowReview{}))
+
+	var log []string
+
+	authors, err := New[testUowAuthor](db, recordWrites(&log, "authors"))
+	assert.Nil(t, err)
+
+	books, err := New[testUowBook](db, recordWrites(&log, "books"))
+	assert.Nil(t, err)
+
+	reviews, err := New[testUowReview](db, recordWrites(&log, "reviews"))
+	assert.Nil(t, err)
+
+	t.Run("Inserts parents first and deletes children first", func(t *testing.T) {
+		log = nil
+		uow := NewUnitOfWork(db)
+
+		author := &testUowAuthor{Name: uuid.NewString()}
+		book := &testUowBook{Title: "Dune", Author: author}
+		review := &testUowReview{Book: book, Stars: 5}
+
+		assert.Nil(t, reviews.Enlist(uow).RegisterNew(review))
+		assert.Nil(t, books.Enlist(uow).RegisterNew(book))
+		assert.Nil(t, authors.Enlist(uow).RegisterNew(author))
+
+		assert.Nil(t, uow.Commit())
+		assert.Equal(t, []string{"create authors", "create books", "create reviews"}, log)
+
+		assert.NotZero(t, author.ID)
+		assert.Equal(t, author.ID, book.AuthorID)
+		assert.Equal(t, book.ID, review.BookID)
+
+		log = nil
+		uow = NewUnitOfWork(db)
+
+		assert.Nil(t, authors.Enlist(uow).RegisterRemoved(author))
+		assert.Nil(t, books.Enlist(uow).RegisterDirty(book))
+		assert.Nil(t, books.Enlist(uow).RegisterRemoved(book))
+		assert.Nil(t, reviews.Enlist(uow).RegisterRemoved(review))
+
+		assert.Nil(t, uow.Commit())
+		assert.Equal(t, []string{"delete reviews", "delete books", "delete authors"}, log)
+
+		_, err := authors.GetById(author.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("Updates in registration order", func(t *testing.T) {
+		author := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, authors.Create(author))
+
+		book := &testUowBook{Title: "Draft", AuthorID: author.ID}
+		assert.Nil(t, books.Create(book))
+
+		log = nil
+		uow := NewUnitOfWork(db)
+
+		book.Title = "Final"
+		author.Name = uuid.NewString()
+		assert.Nil(t, books.Enlist(uow).RegisterDirty(book))
+		assert.Nil(t, authors.Enlist(uow).RegisterDirty(author))
+
+		assert.Nil(t, uow.Commit())
+		assert.Equal(t, []string{"update books", "update authors"}, log)
+
+		stored, err := books.GetById(book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "Final", stored.Title)
+	})
+
+	t.Run("Merged registrations", func(t *testing.T) {
+		log = nil
+		uow := NewUnitOfWork(db)
+		enlisted := authors.Enlist(uow)
+
+		created := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, enlisted.RegisterNew(created))
+		assert.Nil(t, enlisted.RegisterDirty(created))
+
+		canceled := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, enlisted.RegisterNew(canceled))
+		assert.Nil(t, enlisted.RegisterRemoved(canceled))
+
+		removed := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, authors.Create(removed))
+		assert.Nil(t, enlisted.RegisterRemoved(removed))
+		assert.Equal(t, "the entity is registered as removed", enlisted.RegisterDirty(removed).Error())
+
+		assert.Equal(t, "the entity should not be nil", enlisted.RegisterNew(nil).Error())
+
+		log = nil
+		assert.Nil(t, uow.Commit())
+		assert.Equal(t, []string{"create authors", "delete authors"}, log)
+		assert.Zero(t, canceled.ID)
+	})
+
+	t.Run("Failure rolls back every change", func(t *testing.T) {
+		existing := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, authors.Create(existing))
+
+		uow := NewUnitOfWork(db)
+		enlisted := authors.Enlist(uow)
+
+		first := &testUowAuthor{Name: uuid.NewString()}
+		assert.Nil(t, enlisted.RegisterNew(first))
+		assert.Nil(t, enlisted.RegisterNew(&testUowAuthor{Name: existing.Name}))
+
+		assert.NotNil(t, uow.Commit())
+
+		count, err := authors.Count("name = ?", first.Name)
+		assert.Nil(t, err)
+		assert.Zero(t, count)
+
+		// The changes stay registered until they are discarded.
+		assert.Len(t, uow.changes, 2)
+		uow.Rollback()
+		assert.Empty(t, uow.changes)
+		assert.Nil(t, uow.Commit())
+	})
+}
+
+func TestUnitOfWork_Retry(t *testing.T) {
+	db := getGormConnection(t, &testUowAuthor{})
+	assert.Nil(t, db.AutoMigrate(&testUowBook{}))
+
+	authors, err := New[testUowAuthor](db, WithCache(NewMemoryCache(), 0))
+	assert.Nil(t, err)
+
+	// The first insert of a book fails, after its author was inserted.
+	failures := 1
+	books, err := New[testUowBook](db, WithHooks(Hooks{
+		BeforeCreate: func(tx *gorm.DB, entity interface{}) error {
+			if failures > 0 {
+				failures--
+				return errors.New("unavailable")
+			}
+
+			return nil
+		},
+	}))
+	assert.Nil(t, err)
+
+	cached := &testUowAuthor{Name: uuid.NewString()}
+	assert.Nil(t, authors.Create(cached))
+	_, err = authors.GetById(cached.ID)
+	assert.Nil(t, err)
+
+	uow := NewUnitOfWork(db)
+
+	author := &testUowAuthor{Name: uuid.NewString()}
+	book := &testUowBook{Title: "Dune", Author: author}
+	assert.Nil(t, authors.Enlist(uow).RegisterNew(author))
+	assert.Nil(t, books.Enlist(uow).RegisterNew(book))
+
+	cached.Name = uuid.NewString()
+	assert.Nil(t, authors.Enlist(uow).RegisterDirty(cached))
+
+	assert.Equal(t, "unavailable", uow.Commit().Error())
+	assert.Zero(t, author.ID)
+	assert.Zero(t, book.ID)
+
+	// The cached entity is only invalidated by a committed transaction.
+	_, ok := authors.config.cache.Get(authors.cacheKey(cached.ID))
+	assert.True(t, ok)
+
+	assert.Nil(t, uow.Commit())
+	assert.NotZero(t, author.ID)
+	assert.Equal(t, author.ID, book.AuthorID)
+
+	count, err := authors.Count("name = ?", author.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, ok = authors.config.cache.Get(authors.cacheKey(cached.ID))
+	assert.False(t, ok)
+
+	stored, err := authors.GetById(cached.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, cached.Name, stored.Name)
+}
+
+func TestDependencyRank(t *testing.T) {
+	db := getGormConnection(t, &testUowAuthor{})
+
+	authors, err := New[testUowAuthor](db)
+	assert.Nil(t, err)
+
+	books, err := New[testUowBook](db)
+	assert.Nil(t, err)
+
+	reviews, err := New[testUowReview](db)
+	assert.Nil(t, err)
+
+	changes := []*unitChange{
+		{schema: reviews.schema},
+		{schema: books.schema},
+		{schema: authors.schema},
+	}
+
+	rank := dependencyRank(changes)
+	assert.True(t, rank[authors.schema.Table] < rank[books.schema.Table])
+	assert.True(t, rank[books.schema.Table] < rank[reviews.schema.Table])
+
+	t.Run("Unrelated tables keep the registration order", func(t *testing.T) {
+		counters, err := New[testCounter](db)
+		assert.Nil(t, err)
+
+		rank := dependencyRank([]*unitChange{{schema: counters.schema}, {schema: authors.schema}})
+		assert.Equal(t, map[string]int{counters.schema.Table: 0, authors.schema.Table: 1}, rank)
+	})
+}
@@ -1,6 +1,10 @@
 package gormet
 
-import "errors"
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
 
 // Create inserts a new entity of type T into the database.
 //
@@ -29,10 +33,16 @@ func (r *Repository[T]) Create(entity *T) error {
 		return errors.New("the entity should not be nil")
 	}
 
-	if result := r.db.Create(entity); result.Error != nil {
-		return result.Error
+	err := r.write(opCreate, entity, func(tx *gorm.DB) error {
+		return tx.Create(entity).Error
+	})
+
+	if err != nil {
+		return err
 	}
 
+	r.trackWritten(entity)
+
 	return nil
 }
 
@@ -40,7 +50,8 @@ func (r *Repository[T]) Create(entity *T) error {
 //
 // This method ensures the entity is not nil before attempting to update it in the database.
 // It uses GORM's Save method, which updates the entity's data in the corresponding
-// table in the database. If the operation is successful, it returns nil, indicating no error occurred.
+// table in the database, and removes the entity from the cache when caching is enabled.
+// On a repository returned by Tracked, only the changed columns of a tracked entity are written. If the operation is successful, it returns nil, indicating no error occurred.
 // If the operation fails, it returns an error, which could be due to constraints like unique violations,
 // missing required fields, or database connectivity issues.
 //
@@ -63,9 +74,21 @@ func (r *Repository[T]) Update(entity *T) error {
 		return errors.New("the entity should not be nil")
 	}
 
-	if result := r.db.Save(entity); result.Error != nil {
-		return result.Error
+	// A tracked entity only has its changed columns written.
+	if tracked, err := r.updateTracked(entity); tracked {
+		return err
 	}
 
+	err := r.write(opUpdate, entity, func(tx *gorm.DB) error {
+		return tx.Save(entity).Error
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.trackWritten(entity)
+	r.invalidate(r.primaryKeyValue(entity))
+
 	return nil
 }